@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// PKCS7Pad pads the data to a multiple of blockSize bytes. A full block of
+// padding is added when the data is already aligned.
+func PKCS7Pad(data []byte, blockSize int) []byte {
+	n := blockSize - len(data)%blockSize
+	return append(append([]byte{}, data...), RepeatedBytes(byte(n), n)...)
+}
+
+// PKCS7Unpad strips and checks PKCS#7 padding.
+func PKCS7Unpad(data []byte, blockSize int) ([]byte, error) {
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, fmt.Errorf("padded data of length %d isn't a multiple of %d", len(data), blockSize)
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize {
+		return nil, errors.New("invalid PKCS#7 padding")
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, errors.New("invalid PKCS#7 padding")
+		}
+	}
+	return data[:len(data)-n], nil
+}
+
+// EncryptAESCBC pads the plaintext with PKCS#7 and encrypts it with AES in
+// CBC mode. Every plaintext block is XOR'd with the previous ciphertext block,
+// or the IV for the first one, before going through the cipher.
+func EncryptAESCBC(plaintext, key, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("IV of length %d, want %d", len(iv), aes.BlockSize)
+	}
+	padded := PKCS7Pad(plaintext, aes.BlockSize)
+	ciphertext := make([]byte, len(padded))
+	previous := iv
+	for i := 0; i < len(padded); i += aes.BlockSize {
+		block.Encrypt(ciphertext[i:i+aes.BlockSize], FixedXOR(padded[i:i+aes.BlockSize], previous))
+		previous = ciphertext[i : i+aes.BlockSize]
+	}
+	return ciphertext, nil
+}
+
+// DecryptAESCBC decrypts AES-CBC and strips the PKCS#7 padding.
+func DecryptAESCBC(ciphertext, key, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("IV of length %d, want %d", len(iv), aes.BlockSize)
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext of length %d isn't a multiple of %d", len(ciphertext), aes.BlockSize)
+	}
+	plaintext := make([]byte, len(ciphertext))
+	previous := iv
+	for i := 0; i < len(ciphertext); i += aes.BlockSize {
+		block.Decrypt(plaintext[i:i+aes.BlockSize], ciphertext[i:i+aes.BlockSize])
+		copy(plaintext[i:i+aes.BlockSize], FixedXOR(plaintext[i:i+aes.BlockSize], previous))
+		previous = ciphertext[i : i+aes.BlockSize]
+	}
+	return PKCS7Unpad(plaintext, aes.BlockSize)
+}
+
+// AESCTR encrypts or decrypts data with AES in CTR mode. The keystream is
+// the encryption of the 64-bit little-endian nonce followed by the 64-bit
+// little-endian block counter.
+func AESCTR(data, key []byte, nonce uint64) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	counterBlock := make([]byte, aes.BlockSize)
+	keystream := make([]byte, aes.BlockSize)
+	binary.LittleEndian.PutUint64(counterBlock, nonce)
+	for i := 0; i < len(data); i += aes.BlockSize {
+		binary.LittleEndian.PutUint64(counterBlock[8:], uint64(i/aes.BlockSize))
+		block.Encrypt(keystream, counterBlock)
+		for j := i; j < len(data) && j < i+aes.BlockSize; j++ {
+			out[j] = data[j] ^ keystream[j-i]
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPKCS7Pad(t *testing.T) {
+	assert.Equal(t, []byte("YELLOW SUBMARINE\x04\x04\x04\x04"), PKCS7Pad([]byte("YELLOW SUBMARINE"), 20))
+	assert.Equal(t, append([]byte("YELLOW SUBMARINE"), RepeatedBytes(16, 16)...), PKCS7Pad([]byte("YELLOW SUBMARINE"), 16))
+
+	got, err := PKCS7Unpad([]byte("ICE ICE BABY\x04\x04\x04\x04"), 16)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ICE ICE BABY"), got)
+	_, err = PKCS7Unpad([]byte("ICE ICE BABY\x05\x05\x05\x05"), 16)
+	assert.Error(t, err)
+	_, err = PKCS7Unpad([]byte("ICE ICE BABY\x01\x02\x03\x04"), 16)
+	assert.Error(t, err)
+	_, err = PKCS7Unpad([]byte("ICE ICE BABY\x00\x00\x00\x00"), 16)
+	assert.Error(t, err)
+}
+
+func TestAESCBC(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	iv := make([]byte, 16)
+	plaintext := []byte("I'm back and I'm ringin' the bell \nA rockin' on the mike while the fly girls yell")
+
+	ciphertext, err := EncryptAESCBC(plaintext, key, iv)
+	assert.NoError(t, err)
+
+	// compare with the standard library
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	want := PKCS7Pad(plaintext, 16)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(want, want)
+	assert.Equal(t, want, ciphertext)
+
+	got, err := DecryptAESCBC(ciphertext, key, iv)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	_, err = EncryptAESCBC(plaintext, key, iv[:8])
+	assert.Error(t, err)
+	_, err = DecryptAESCBC(ciphertext[:20], key, iv)
+	assert.Error(t, err)
+}
+
+func TestAESCTR(t *testing.T) {
+	ciphertext, err := base64.StdEncoding.DecodeString("L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==")
+	assert.NoError(t, err)
+	key := []byte("YELLOW SUBMARINE")
+	plaintext, err := AESCTR(ciphertext, key, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby ", string(plaintext))
+
+	again, err := AESCTR(plaintext, key, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, ciphertext, again)
+}
@@ -0,0 +1,253 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync/atomic"
+)
+
+// PaddingOracleServer decrypts RSA ciphertexts and only tells whether the
+// plaintext is PKCS#1 v1.5 conforming, i.e. starts with 00 02.
+// It counts the queries it answers.
+type PaddingOracleServer struct {
+	priv    *RSAPrivateKey
+	queries int64
+}
+
+// NewPaddingOracleServer makes a padding oracle for priv.
+func NewPaddingOracleServer(priv *RSAPrivateKey) *PaddingOracleServer {
+	return &PaddingOracleServer{priv: priv}
+}
+
+// PublicKey returns the public key clients should encrypt to.
+func (s *PaddingOracleServer) PublicKey() *RSAPublicKey {
+	return &s.priv.RSAPublicKey
+}
+
+// Conforming reports whether c decrypts to a block starting with 00 02.
+func (s *PaddingOracleServer) Conforming(c *big.Int) bool {
+	atomic.AddInt64(&s.queries, 1)
+	block := leftPad(s.priv.Decrypt(c), s.priv.Size())
+	return block[0] == 0x00 && block[1] == 0x02
+}
+
+// Queries returns the number of queries answered so far.
+func (s *PaddingOracleServer) Queries() int64 {
+	return atomic.LoadInt64(&s.queries)
+}
+
+// interval is the closed range [a, b].
+type interval struct {
+	a, b *big.Int
+}
+
+// ceilDiv returns ceil(x / y) for positive y.
+func ceilDiv(x, y *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(x, y, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, bigOne)
+	}
+	return q
+}
+
+// unionIntervals adds [a, b] to the sorted, disjoint intervals, merging
+// whatever overlaps.
+func unionIntervals(intervals []interval, a, b *big.Int) []interval {
+	var out []interval
+	inserted := false
+	for _, in := range intervals {
+		switch {
+		case in.b.Cmp(a) < 0:
+			out = append(out, in)
+		case in.a.Cmp(b) > 0:
+			if !inserted {
+				out = append(out, interval{a, b})
+				inserted = true
+			}
+			out = append(out, in)
+		default:
+			// overlap, grow [a, b] and keep going
+			if in.a.Cmp(a) < 0 {
+				a = in.a
+			}
+			if in.b.Cmp(b) > 0 {
+				b = in.b
+			}
+		}
+	}
+	if !inserted {
+		out = append(out, interval{a, b})
+	}
+	return out
+}
+
+// bleichenbacher holds the state of one run of the attack.
+type bleichenbacher struct {
+	ctx        context.Context
+	pub        *RSAPublicKey
+	conforming func(*big.Int) bool
+	c0         *big.Int
+	twoB       *big.Int
+	threeB     *big.Int
+}
+
+// query asks the oracle whether c0 * s**e is conforming.
+func (bb *bleichenbacher) query(s *big.Int) (bool, error) {
+	if err := bb.ctx.Err(); err != nil {
+		return false, err
+	}
+	c := new(big.Int).Mul(bb.c0, bb.pub.Encrypt(s))
+	c.Mod(c, bb.pub.N)
+	return bb.conforming(c), nil
+}
+
+// searchFrom returns the smallest s >= start that gives a conforming
+// ciphertext (steps 2a and 2b).
+func (bb *bleichenbacher) searchFrom(start *big.Int) (*big.Int, error) {
+	s := new(big.Int).Set(start)
+	for {
+		ok, err := bb.query(s)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return s, nil
+		}
+		s.Add(s, bigOne)
+	}
+}
+
+// searchOneInterval finds the next s when a single interval [a, b] is left
+// (step 2c). It walks r upwards and tries every s in
+// [(2B + r*n)/b, (3B + r*n)/a).
+func (bb *bleichenbacher) searchOneInterval(in interval, previous *big.Int) (*big.Int, error) {
+	n := bb.pub.N
+	r := new(big.Int).Mul(in.b, previous)
+	r.Sub(r, bb.twoB)
+	r.Lsh(r, 1)
+	r = ceilDiv(r, n)
+	for {
+		rn := new(big.Int).Mul(r, n)
+		low := ceilDiv(new(big.Int).Add(bb.twoB, rn), in.b)
+		high := ceilDiv(new(big.Int).Add(bb.threeB, rn), in.a)
+		for s := low; s.Cmp(high) < 0; s.Add(s, bigOne) {
+			ok, err := bb.query(s)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return s, nil
+			}
+		}
+		r.Add(r, bigOne)
+	}
+}
+
+// narrow computes the intervals that can still contain m after finding a
+// conforming s (step 3).
+func (bb *bleichenbacher) narrow(intervals []interval, s *big.Int) []interval {
+	n := bb.pub.N
+	threeBMinusOne := new(big.Int).Sub(bb.threeB, bigOne)
+	var out []interval
+	for _, in := range intervals {
+		// r from (a*s - 3B + 1)/n to (b*s - 2B)/n
+		rLow := new(big.Int).Mul(in.a, s)
+		rLow.Sub(rLow, threeBMinusOne)
+		rLow = ceilDiv(rLow, n)
+		rHigh := new(big.Int).Mul(in.b, s)
+		rHigh.Sub(rHigh, bb.twoB)
+		rHigh.Div(rHigh, n)
+		for r := rLow; r.Cmp(rHigh) <= 0; r = new(big.Int).Add(r, bigOne) {
+			rn := new(big.Int).Mul(r, n)
+			a := ceilDiv(new(big.Int).Add(bb.twoB, rn), s)
+			if a.Cmp(in.a) < 0 {
+				a = in.a
+			}
+			b := new(big.Int).Add(threeBMinusOne, rn)
+			b.Div(b, s)
+			if b.Cmp(in.b) > 0 {
+				b = in.b
+			}
+			if a.Cmp(b) <= 0 {
+				out = unionIntervals(out, a, b)
+			}
+		}
+	}
+	return out
+}
+
+// BleichenbacherAttack decrypts c with a PKCS#1 v1.5 padding oracle,
+// following Bleichenbacher's "Chosen Ciphertext Attacks Against Protocols
+// Based on the RSA Encryption Standard PKCS #1" (CRYPTO '98).
+// Every conforming c*s**e tells that m*s mod n lies in [2B, 3B), which
+// narrows down the set of intervals containing m until a single value is left.
+// Large moduli take many queries, so the attack gives up when ctx is done.
+// Link: https://cryptopals.com/sets/6/challenges/48
+func BleichenbacherAttack(ctx context.Context, pub *RSAPublicKey, c *big.Int, conforming func(*big.Int) bool) (*big.Int, error) {
+	n := pub.N
+	k := pub.Size()
+	if k < 11 {
+		return nil, errors.New("modulus is too small for PKCS#1 v1.5")
+	}
+	bigB := new(big.Int).Lsh(bigOne, uint(8*(k-2)))
+	bb := &bleichenbacher{
+		ctx:        ctx,
+		pub:        pub,
+		conforming: conforming,
+		c0:         new(big.Int).Set(c),
+		twoB:       new(big.Int).Mul(bigTwo, bigB),
+		threeB:     new(big.Int).Mul(big.NewInt(3), bigB),
+	}
+
+	// step 1: blinding, skipped when c is already conforming
+	s0 := big.NewInt(1)
+	for {
+		ok, err := bb.query(s0)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+		s0, err = rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	bb.c0.Mul(bb.c0, pub.Encrypt(s0))
+	bb.c0.Mod(bb.c0, n)
+
+	intervals := []interval{{new(big.Int).Set(bb.twoB), new(big.Int).Sub(bb.threeB, bigOne)}}
+	var s *big.Int
+	for i := 1; ; i++ {
+		var err error
+		switch {
+		case i == 1:
+			// step 2a: start at n/3B, anything smaller can't be conforming
+			s, err = bb.searchFrom(ceilDiv(n, bb.threeB))
+		case len(intervals) > 1:
+			// step 2b
+			s, err = bb.searchFrom(new(big.Int).Add(s, bigOne))
+		default:
+			// step 2c
+			s, err = bb.searchOneInterval(intervals[0], s)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = bb.narrow(intervals, s)
+		if len(intervals) == 0 {
+			return nil, errors.New("no interval left, the oracle is inconsistent")
+		}
+
+		// step 4
+		if len(intervals) == 1 && intervals[0].a.Cmp(intervals[0].b) == 0 {
+			m := new(big.Int).ModInverse(s0, n)
+			m.Mul(m, intervals[0].a)
+			return m.Mod(m, n), nil
+		}
+	}
+}
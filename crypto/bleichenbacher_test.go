@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaddingOracleServer(t *testing.T) {
+	priv, err := GenerateRSAKey(256, 3)
+	assert.NoError(t, err)
+	server := NewPaddingOracleServer(priv)
+
+	block, err := PadPKCS1v15Encryption([]byte("kick it, CC"), priv.Size())
+	assert.NoError(t, err)
+	assert.True(t, server.Conforming(priv.Encrypt(new(big.Int).SetBytes(block))))
+	block[1] = 0x01
+	assert.False(t, server.Conforming(priv.Encrypt(new(big.Int).SetBytes(block))))
+	assert.Equal(t, int64(2), server.Queries())
+}
+
+func TestUnionIntervals(t *testing.T) {
+	var intervals []interval
+	intervals = unionIntervals(intervals, big.NewInt(10), big.NewInt(20))
+	intervals = unionIntervals(intervals, big.NewInt(30), big.NewInt(40))
+	intervals = unionIntervals(intervals, big.NewInt(1), big.NewInt(2))
+	assert.Len(t, intervals, 3)
+	intervals = unionIntervals(intervals, big.NewInt(15), big.NewInt(35))
+	assert.Len(t, intervals, 2)
+	assert.Equal(t, int64(1), intervals[0].a.Int64())
+	assert.Equal(t, int64(10), intervals[1].a.Int64())
+	assert.Equal(t, int64(40), intervals[1].b.Int64())
+}
+
+func TestBleichenbacherAttack(t *testing.T) {
+	tests := []struct {
+		bits int
+		msg  string
+	}{
+		{256, "kick it, CC"},
+		{768, "Roll with the punches, I'm cool, like a breeze"},
+	}
+	for _, test := range tests {
+		if test.bits > 256 && testing.Short() {
+			continue
+		}
+		priv, err := GenerateRSAKey(test.bits, 3)
+		assert.NoError(t, err)
+		server := NewPaddingOracleServer(priv)
+		block, err := PadPKCS1v15Encryption([]byte(test.msg), priv.Size())
+		assert.NoError(t, err)
+		c := priv.Encrypt(new(big.Int).SetBytes(block))
+
+		m, err := BleichenbacherAttack(context.Background(), server.PublicKey(), c, server.Conforming)
+		assert.NoError(t, err)
+		got, err := UnpadPKCS1v15Encryption(leftPad(m, priv.Size()))
+		assert.NoError(t, err)
+		assert.Equal(t, test.msg, string(got))
+		t.Logf("%d bit modulus: %d oracle queries", test.bits, server.Queries())
+	}
+}
+
+func TestBleichenbacherAttackCancel(t *testing.T) {
+	priv, err := GenerateRSAKey(768, 3)
+	assert.NoError(t, err)
+	server := NewPaddingOracleServer(priv)
+	block, err := PadPKCS1v15Encryption([]byte("kick it, CC"), priv.Size())
+	assert.NoError(t, err)
+	c := priv.Encrypt(new(big.Int).SetBytes(block))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = BleichenbacherAttack(ctx, server.PublicKey(), c, server.Conforming)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,314 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrBadMAC is returned when a request's CBC-MAC doesn't check out.
+var ErrBadMAC = errors.New("CBC-MAC mismatch")
+
+// CBCMAC is the last block of the AES-CBC encryption of the PKCS#7 padded msg.
+func CBCMAC(msg, key, iv []byte) ([]byte, error) {
+	ciphertext, err := EncryptAESCBC(msg, key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return ciphertext[len(ciphertext)-aes.BlockSize:], nil
+}
+
+// Transaction moves an amount of spacebucks to an account.
+type Transaction struct {
+	To     string
+	Amount int
+}
+
+// Transfer is a parsed transfer request.
+type Transfer struct {
+	From         string
+	Transactions []Transaction
+}
+
+// TransferServer is the bank API. It shares the MAC key with its clients and
+// executes any request with a valid CBC-MAC.
+type TransferServer struct {
+	key []byte
+}
+
+// NewTransferServer makes an API server with the MAC key.
+func NewTransferServer(key []byte) *TransferServer {
+	return &TransferServer{key}
+}
+
+// HandleIVRequest checks and parses a request of the form message || IV || MAC
+// with the message from=#{from_id}&to=#{to_id}&amount=#{amount}.
+func (s *TransferServer) HandleIVRequest(req []byte) (*Transfer, error) {
+	if len(req) < 2*aes.BlockSize {
+		return nil, errors.New("request is too short")
+	}
+	msg := req[:len(req)-2*aes.BlockSize]
+	iv := req[len(req)-2*aes.BlockSize : len(req)-aes.BlockSize]
+	mac := req[len(req)-aes.BlockSize:]
+	want, err := CBCMAC(msg, s.key, iv)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, want) {
+		return nil, ErrBadMAC
+	}
+
+	params, err := parseParams(string(msg))
+	if err != nil {
+		return nil, err
+	}
+	amount, err := strconv.Atoi(params["amount"])
+	if err != nil {
+		return nil, fmt.Errorf("bad amount %q", params["amount"])
+	}
+	return &Transfer{params["from"], []Transaction{{params["to"], amount}}}, nil
+}
+
+// HandleMultiRequest checks and parses a request of the form message || MAC,
+// MAC'd with a zero IV, with the message
+// from=#{from_id}&tx_list=#{to:amount(;to:amount)*}.
+// Like plenty of real servers it skips transactions it can't parse.
+func (s *TransferServer) HandleMultiRequest(req []byte) (*Transfer, error) {
+	if len(req) < aes.BlockSize {
+		return nil, errors.New("request is too short")
+	}
+	msg := req[:len(req)-aes.BlockSize]
+	mac := req[len(req)-aes.BlockSize:]
+	want, err := CBCMAC(msg, s.key, make([]byte, aes.BlockSize))
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, want) {
+		return nil, ErrBadMAC
+	}
+
+	// everything after the first &tx_list= is the list
+	parts := strings.SplitN(string(msg), "&tx_list=", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "from=") {
+		return nil, errors.New("malformed multi-transaction request")
+	}
+	transfer := &Transfer{From: strings.TrimPrefix(parts[0], "from=")}
+	for _, entry := range strings.Split(parts[1], ";") {
+		fields := strings.Split(entry, ":")
+		if len(fields) != 2 {
+			continue
+		}
+		amount, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		transfer.Transactions = append(transfer.Transactions, Transaction{fields[0], amount})
+	}
+	return transfer, nil
+}
+
+// parseParams splits k=v&k=v. Only the first = of a pair separates the key,
+// so values may contain = but not &.
+func parseParams(s string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, pair := range strings.Split(s, "&") {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("malformed parameter %q", pair)
+		}
+		params[pair[:i]] = pair[i+1:]
+	}
+	if params["from"] == "" {
+		return nil, errors.New("request has no from parameter")
+	}
+	return params, nil
+}
+
+// TransferClient builds requests for the owner of an account. It only ever
+// signs requests that move money out of its own account.
+type TransferClient struct {
+	key     []byte
+	Account string
+}
+
+// NewTransferClient makes a client for the account with the MAC key.
+func NewTransferClient(key []byte, account string) *TransferClient {
+	return &TransferClient{key, account}
+}
+
+// IVRequest makes a message || IV || MAC request with a random IV.
+func (c *TransferClient) IVRequest(to string, amount int) ([]byte, error) {
+	msg := fmt.Sprintf("from=%s&to=%s&amount=%d", c.Account, to, amount)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	mac, err := CBCMAC([]byte(msg), c.key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(msg), iv...), mac...), nil
+}
+
+// MultiRequest makes a message || MAC request with a zero IV.
+func (c *TransferClient) MultiRequest(txs []Transaction) ([]byte, error) {
+	var entries []string
+	for _, tx := range txs {
+		entries = append(entries, fmt.Sprintf("%s:%d", tx.To, tx.Amount))
+	}
+	msg := fmt.Sprintf("from=%s&tx_list=%s", c.Account, strings.Join(entries, ";"))
+	mac, err := CBCMAC([]byte(msg), c.key, make([]byte, aes.BlockSize))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(msg), mac...), nil
+}
+
+// ForgeIVRequest rewrites the from field of a message || IV || MAC request.
+// Only the first block changes, and the IV absorbs the change: the first
+// block goes into the cipher as P1 ^ IV, so P1' ^ IV' with IV' = IV ^ P1 ^ P1'
+// gives the same MAC. The new account must have as many characters as the
+// old one and the field must sit in the first block.
+// Link: https://cryptopals.com/sets/7/challenges/49
+func ForgeIVRequest(req []byte, from string) ([]byte, error) {
+	if len(req) < 3*aes.BlockSize {
+		return nil, errors.New("request is too short to forge")
+	}
+	msg := req[:len(req)-2*aes.BlockSize]
+	iv := req[len(req)-2*aes.BlockSize : len(req)-aes.BlockSize]
+	mac := req[len(req)-aes.BlockSize:]
+	end := bytes.IndexByte(msg, '&')
+	if !bytes.HasPrefix(msg, []byte("from=")) || end < 0 {
+		return nil, errors.New("request doesn't start with a from field")
+	}
+	if end-len("from=") != len(from) || end > aes.BlockSize {
+		return nil, fmt.Errorf("can't swap in account %q within the first block", from)
+	}
+
+	forged := append([]byte("from="+from), msg[end:]...)
+	delta := FixedXOR(msg[:aes.BlockSize], forged[:aes.BlockSize])
+	forgedIV := FixedXOR(iv, delta)
+	return append(append(forged, forgedIV...), mac...), nil
+}
+
+// ForgeMultiRequest glues the attacker's own request onto a captured one with
+// a fixed IV. CBC-MAC of the captured message ends in the state mac1, so
+// appending own[0:16] ^ mac1 puts the chain back at the state it has after
+// own's first block, and the rest of own leads to own's MAC.
+// The first block of own turns into garbage in the middle of the captured
+// transaction list; whatever follows it, like ;attacker:1000000, gets parsed.
+// Link: https://cryptopals.com/sets/7/challenges/49
+func ForgeMultiRequest(captured, own []byte) ([]byte, error) {
+	if len(captured) < aes.BlockSize || len(own) <= 2*aes.BlockSize {
+		return nil, errors.New("requests are too short to forge")
+	}
+	capturedMsg := captured[:len(captured)-aes.BlockSize]
+	capturedMAC := captured[len(captured)-aes.BlockSize:]
+	ownMsg := own[:len(own)-aes.BlockSize]
+	ownMAC := own[len(own)-aes.BlockSize:]
+
+	forged := PKCS7Pad(capturedMsg, aes.BlockSize)
+	forged = append(forged, FixedXOR(ownMsg[:aes.BlockSize], capturedMAC)...)
+	forged = append(forged, ownMsg[aes.BlockSize:]...)
+	return append(forged, ownMAC...), nil
+}
+
+// cbcChain runs the CBC chain from state over the block-aligned data and
+// returns the new state, without any padding.
+func cbcChain(block cipher.Block, state, data []byte) []byte {
+	state = append([]byte{}, state...)
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Encrypt(state, FixedXOR(state, data[i:i+aes.BlockSize]))
+	}
+	return state
+}
+
+// cbcMACGlue returns the block that takes the CBC chain from state to the
+// target MAC, counting the full block of padding that follows it:
+// E(E(state ^ glue) ^ pad) = target.
+func cbcMACGlue(block cipher.Block, state, target []byte) []byte {
+	x := make([]byte, aes.BlockSize)
+	block.Decrypt(x, target)
+	x = FixedXOR(x, RepeatedBytes(aes.BlockSize, aes.BlockSize))
+	glue := make([]byte, aes.BlockSize)
+	block.Decrypt(glue, x)
+	return FixedXOR(glue, state)
+}
+
+// ForgeCBCMACPrefix returns payload, PKCS#7 padded, followed by one glue
+// block, such that the whole message has the target CBC-MAC under the key
+// with a zero IV. It's the hash collision of CBC-MAC: whoever knows the key
+// can reach any MAC from any prefix.
+// Link: https://cryptopals.com/sets/7/challenges/50
+func ForgeCBCMACPrefix(target, payload, key []byte) ([]byte, error) {
+	if len(target) != aes.BlockSize {
+		return nil, fmt.Errorf("MAC of length %d, want %d", len(target), aes.BlockSize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padded := PKCS7Pad(payload, aes.BlockSize)
+	state := cbcChain(block, make([]byte, aes.BlockSize), padded)
+	return append(padded, cbcMACGlue(block, state, target)...), nil
+}
+
+// IsPrintable reports whether the data is printable ASCII, space to tilde.
+func IsPrintable(data []byte) bool {
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// ForgeJavaScript makes a JavaScript snippet that runs code and has the
+// target CBC-MAC. The code is followed by a // comment that hides a filler
+// block and the glue block. Random fillers are tried until the glue block is
+// printable too, so the whole snippet is printable ASCII on a single line.
+// That takes around 2**23 tries, each costing one AES call.
+func ForgeJavaScript(target []byte, code string, key []byte) ([]byte, error) {
+	if len(target) != aes.BlockSize {
+		return nil, fmt.Errorf("MAC of length %d, want %d", len(target), aes.BlockSize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	payload := []byte(code + "//")
+	for len(payload)%aes.BlockSize != 0 {
+		payload = append(payload, ' ')
+	}
+	if !IsPrintable(payload) {
+		return nil, errors.New("code isn't printable")
+	}
+	prefixState := cbcChain(block, make([]byte, aes.BlockSize), payload)
+	// the glue is D(x) ^ state, and x doesn't depend on the filler
+	glueBase := cbcMACGlue(block, make([]byte, aes.BlockSize), target)
+
+	filler := make([]byte, aes.BlockSize)
+	random := make([]byte, aes.BlockSize)
+	state := make([]byte, aes.BlockSize)
+	for tries := 0; tries < 1<<28; tries++ {
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		for i := range filler {
+			filler[i] = 0x20 + random[i]%0x5f
+		}
+		block.Encrypt(state, FixedXOR(prefixState, filler))
+		glue := FixedXOR(glueBase, state)
+		if IsPrintable(glue) {
+			forged := append(append(payload, filler...), glue...)
+			return forged, nil
+		}
+	}
+	return nil, errors.New("no printable glue block found")
+}
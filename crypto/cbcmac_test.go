@@ -0,0 +1,133 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBCMAC(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	iv := make([]byte, 16)
+	mac, err := CBCMAC([]byte("alert('MZA who was that?');\n"), key, iv)
+	assert.NoError(t, err)
+	assert.Equal(t, "296b8d7cb78a243dda4d0a61d33bbdd1", hex.EncodeToString(mac))
+}
+
+func TestTransferServer(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	server := NewTransferServer(key)
+	client := NewTransferClient(key, "1001")
+
+	req, err := client.IVRequest("2002", 100)
+	assert.NoError(t, err)
+	transfer, err := server.HandleIVRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, &Transfer{"1001", []Transaction{{"2002", 100}}}, transfer)
+
+	req[0] ^= 1
+	_, err = server.HandleIVRequest(req)
+	assert.Equal(t, ErrBadMAC, err)
+
+	txs := []Transaction{{"2002", 100}, {"3003", 5}}
+	req, err = client.MultiRequest(txs)
+	assert.NoError(t, err)
+	transfer, err = server.HandleMultiRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, &Transfer{"1001", txs}, transfer)
+
+	// a client with another key is turned away
+	mallory := NewTransferClient([]byte("ORANGE SUBMARINE"), "1001")
+	req, err = mallory.MultiRequest(txs)
+	assert.NoError(t, err)
+	_, err = server.HandleMultiRequest(req)
+	assert.Equal(t, ErrBadMAC, err)
+}
+
+func TestForgeIVRequest(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	server := NewTransferServer(key)
+	attacker := NewTransferClient(key, "6666")
+
+	// the attacker sends money from their own account to themselves...
+	req, err := attacker.IVRequest("6666", 1000000)
+	assert.NoError(t, err)
+	// ...and rewrites the source account
+	forged, err := ForgeIVRequest(req, "1001")
+	assert.NoError(t, err)
+	transfer, err := server.HandleIVRequest(forged)
+	assert.NoError(t, err)
+	assert.Equal(t, &Transfer{"1001", []Transaction{{"6666", 1000000}}}, transfer)
+
+	_, err = ForgeIVRequest(req, "100001")
+	assert.Error(t, err)
+}
+
+func TestForgeMultiRequest(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	server := NewTransferServer(key)
+	victim := NewTransferClient(key, "1001")
+	attacker := NewTransferClient(key, "6666")
+
+	captured, err := victim.MultiRequest([]Transaction{{"2002", 100}, {"3003", 5}})
+	assert.NoError(t, err)
+	// the first transaction ends up glued to garbage, the second one survives
+	own, err := attacker.MultiRequest([]Transaction{{"6666", 1}, {"6666", 1000000}})
+	assert.NoError(t, err)
+
+	forged, err := ForgeMultiRequest(captured, own)
+	assert.NoError(t, err)
+	transfer, err := server.HandleMultiRequest(forged)
+	assert.NoError(t, err)
+	assert.Equal(t, "1001", transfer.From)
+	assert.Contains(t, transfer.Transactions, Transaction{"2002", 100})
+	assert.Contains(t, transfer.Transactions, Transaction{"6666", 1000000})
+}
+
+func TestForgeCBCMACPrefix(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	iv := make([]byte, 16)
+	target, err := CBCMAC([]byte("alert('MZA who was that?');\n"), key, iv)
+	assert.NoError(t, err)
+	assert.Equal(t, "296b8d7cb78a243dda4d0a61d33bbdd1", hex.EncodeToString(target))
+
+	for _, payload := range []string{
+		"alert('Ayo, the Wu is back!');",
+		"a payload that's exactly 32 byte",
+	} {
+		forged, err := ForgeCBCMACPrefix(target, []byte(payload), key)
+		assert.NoError(t, err)
+		padded := PKCS7Pad([]byte(payload), 16)
+		assert.Equal(t, padded, forged[:len(padded)])
+		assert.Len(t, forged, len(padded)+16)
+		mac, err := CBCMAC(forged, key, iv)
+		assert.NoError(t, err)
+		assert.Equal(t, target, mac)
+	}
+
+	_, err = ForgeCBCMACPrefix(target[:8], []byte("alert(1)"), key)
+	assert.Error(t, err)
+}
+
+func TestForgeJavaScript(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	iv := make([]byte, 16)
+	target, err := CBCMAC([]byte("alert('MZA who was that?');\n"), key, iv)
+	assert.NoError(t, err)
+
+	code := "alert('Ayo, the Wu is back!');"
+	forged, err := ForgeJavaScript(target, code, key)
+	assert.NoError(t, err)
+	assert.True(t, IsPrintable(forged))
+	assert.Equal(t, code+"//", string(forged[:len(code)+2]))
+	mac, err := CBCMAC(forged, key, iv)
+	assert.NoError(t, err)
+	assert.Equal(t, target, mac)
+}
+
+func TestIsPrintable(t *testing.T) {
+	assert.True(t, IsPrintable([]byte("alert('hi'); // ~")))
+	assert.False(t, IsPrintable([]byte("alert('hi');\n")))
+	assert.False(t, IsPrintable([]byte{0x7f}))
+}
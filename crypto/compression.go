@@ -0,0 +1,147 @@
+package crypto
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// CipherMode picks how the compression oracle encrypts its requests.
+type CipherMode int
+
+// Cipher modes of the compression oracle. A stream cipher leaks the
+// compressed length to the byte, CBC rounds it up to whole blocks.
+const (
+	CTRMode CipherMode = iota
+	CBCMode
+)
+
+// CompressionOracle returns the length of the encrypted request carrying the
+// attacker's payload next to a secret.
+type CompressionOracle func(payload []byte) (int, error)
+
+// base64Alphabet is what session ids are made of, '=' included for padding.
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/="
+
+// compressionFiller is made of characters that appear nowhere in the request
+// headers or the session id, so they never compress against anything there.
+const compressionFiller = "!@#$%^&*()_[]{}<>|~`?,;'\""
+
+// FormatCookieRequest builds the HTTP request that carries the session id
+// and the payload as its body.
+func FormatCookieRequest(sessionID string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("POST / HTTP/1.1\nHost: hapless.com\nCookie: sessionid=%s\nContent-Length: %d\n%s", sessionID, len(payload), payload))
+}
+
+// NewCompressionOracle makes an oracle that compresses the request with
+// DEFLATE and encrypts it under a fresh random key every time.
+func NewCompressionOracle(sessionID string, mode CipherMode) CompressionOracle {
+	return func(payload []byte) (int, error) {
+		var compressed bytes.Buffer
+		w, err := flate.NewWriter(&compressed, flate.BestCompression)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := w.Write(FormatCookieRequest(sessionID, payload)); err != nil {
+			return 0, err
+		}
+		if err := w.Close(); err != nil {
+			return 0, err
+		}
+
+		key := make([]byte, aes.BlockSize)
+		iv := make([]byte, aes.BlockSize)
+		if _, err := rand.Read(key); err != nil {
+			return 0, err
+		}
+		if _, err := rand.Read(iv); err != nil {
+			return 0, err
+		}
+		var ciphertext []byte
+		switch mode {
+		case CTRMode:
+			ciphertext, err = AESCTR(compressed.Bytes(), key, binary.LittleEndian.Uint64(iv))
+		case CBCMode:
+			ciphertext, err = EncryptAESCBC(compressed.Bytes(), key, iv)
+		default:
+			err = fmt.Errorf("unknown cipher mode %d", mode)
+		}
+		if err != nil {
+			return 0, err
+		}
+		return len(ciphertext), nil
+	}
+}
+
+// randomFiller returns n characters drawn from compressionFiller.
+func randomFiller(n int) ([]byte, error) {
+	filler := make([]byte, n)
+	max := big.NewInt(int64(len(compressionFiller)))
+	for i := range filler {
+		j, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, err
+		}
+		filler[i] = compressionFiller[j.Int64()]
+	}
+	return filler, nil
+}
+
+// RecoverCompressedSecret recovers the session id from a compression oracle,
+// CRIME style. The payload known+guess+c compresses best when it repeats the
+// cookie line, so the right c gives the shortest request.
+// The difference is a few bits at most, which byte granularity or CBC block
+// granularity can hide, so every guess is also tried behind filler of growing
+// length made of characters that never compress. Some filler length puts the
+// compressed size right at a boundary, where only the right guess stays
+// below it. The search ends when the next character is the newline that
+// closes the cookie line.
+// Link: https://cryptopals.com/sets/7/challenges/51
+func RecoverCompressedSecret(oracle CompressionOracle, known string, maxLength int) (string, error) {
+	candidates := base64Alphabet + "\n"
+	guess := ""
+	for len(guess) < maxLength {
+		c, err := nextCompressedChar(oracle, known+guess, candidates)
+		if err != nil {
+			return guess, err
+		}
+		if c == '\n' {
+			return guess, nil
+		}
+		guess += string(c)
+	}
+	return guess, fmt.Errorf("secret is longer than %d characters", maxLength)
+}
+
+// nextCompressedChar finds the candidate that compresses best after prefix.
+func nextCompressedChar(oracle CompressionOracle, prefix, candidates string) (byte, error) {
+	for n := 0; n < 4*aes.BlockSize; n++ {
+		filler, err := randomFiller(n)
+		if err != nil {
+			return 0, err
+		}
+		best := -1
+		var winner byte
+		for i := 0; i < len(candidates); i++ {
+			length, err := oracle(append(append(append([]byte{}, filler...), prefix...), candidates[i]))
+			if err != nil {
+				return 0, err
+			}
+			switch {
+			case best < 0 || length < best:
+				best, winner = length, candidates[i]
+			case length == best:
+				winner = 0
+			}
+		}
+		if winner != 0 {
+			return winner, nil
+		}
+	}
+	return 0, errors.New("no filler length separates the candidates")
+}
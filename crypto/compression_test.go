@@ -0,0 +1,41 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sessionID = "TmV2ZXIgcmV2ZWFsIHRoZSBXdS1UYW5nIFNlY3JldCE="
+
+func TestCompressionOracle(t *testing.T) {
+	assert.Equal(t, "POST / HTTP/1.1\nHost: hapless.com\nCookie: sessionid=abc\nContent-Length: 2\nhi", string(FormatCookieRequest("abc", []byte("hi"))))
+
+	// repeating the secret compresses better than repeating something else
+	oracle := NewCompressionOracle(sessionID, CTRMode)
+	right, err := oracle([]byte("sessionid=" + sessionID))
+	assert.NoError(t, err)
+	wrong, err := oracle([]byte("sessionid=" + sessionID[22:] + sessionID[:22]))
+	assert.NoError(t, err)
+	assert.Less(t, right, wrong)
+
+	// CBC only leaks whole blocks
+	length, err := NewCompressionOracle(sessionID, CBCMode)([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, length%16)
+}
+
+func TestCompressionFiller(t *testing.T) {
+	request := string(FormatCookieRequest(base64Alphabet, nil))
+	assert.False(t, strings.ContainsAny(request, compressionFiller))
+}
+
+func TestRecoverCompressedSecret(t *testing.T) {
+	for _, mode := range []CipherMode{CTRMode, CBCMode} {
+		oracle := NewCompressionOracle(sessionID, mode)
+		got, err := RecoverCompressedSecret(oracle, "sessionid=", 64)
+		assert.NoError(t, err)
+		assert.Equal(t, sessionID, got)
+	}
+}
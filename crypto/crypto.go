@@ -43,7 +43,7 @@ func DecryptSingleXOR(ciphertext []byte) []Guess {
 		b2 := RepeatedBytes(byte(i), len(ciphertext))
 		xored := string(FixedXOR(ciphertext, b2))
 		prob := Chi2Probability(strings.ToLower(xored))
-		guesses = append(guesses, Guess{string(i), prob, xored})
+		guesses = append(guesses, Guess{string(rune(i)), prob, xored})
 	}
 	sort.Slice(guesses,
 		func(i, j int) bool { return guesses[i].Probability < guesses[j].Probability })
@@ -0,0 +1,82 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// DHGroup is a Diffie-Hellman group: the generator G has prime order Q in
+// the multiplicative group mod P.
+type DHGroup struct {
+	P, G, Q *big.Int
+}
+
+// DHPrivateKey is a Diffie-Hellman key pair, Y = G**X mod P.
+type DHPrivateKey struct {
+	Group DHGroup
+	X, Y  *big.Int
+}
+
+// Validate checks that G is not trivial and has order Q.
+func (g *DHGroup) Validate() error {
+	if g.G.Cmp(bigOne) <= 0 || g.G.Cmp(g.P) >= 0 {
+		return errors.New("generator out of range")
+	}
+	if new(big.Int).Exp(g.G, g.Q, g.P).Cmp(bigOne) != 0 {
+		return errors.New("generator doesn't have order q")
+	}
+	return nil
+}
+
+// GenerateDHKey picks a random private key in [1, Q).
+func GenerateDHKey(group DHGroup) (*DHPrivateKey, error) {
+	x, err := rand.Int(rand.Reader, new(big.Int).Sub(group.Q, bigOne))
+	if err != nil {
+		return nil, err
+	}
+	x.Add(x, bigOne)
+	return &DHPrivateKey{group, x, new(big.Int).Exp(group.G, x, group.P)}, nil
+}
+
+// SharedSecret returns the other party's public key raised to X. Nothing
+// checks that it belongs to the group.
+func (priv *DHPrivateKey) SharedSecret(y *big.Int) *big.Int {
+	return new(big.Int).Exp(y, priv.X, priv.Group.P)
+}
+
+// DHMAC is the HMAC-SHA256 of msg keyed with a shared secret.
+func DHMAC(secret *big.Int, msg []byte) []byte {
+	mac := hmac.New(sha256.New, secret.Bytes())
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// DHBob holds a long-term private key and answers any public key with a
+// message MAC'd under the shared secret.
+type DHBob struct {
+	priv *DHPrivateKey
+	msg  []byte
+}
+
+// NewDHBob makes a Bob with a random key in group.
+func NewDHBob(group DHGroup) (*DHBob, error) {
+	priv, err := GenerateDHKey(group)
+	if err != nil {
+		return nil, err
+	}
+	return &DHBob{priv, []byte("crazy flamboyant for the rap enjoyment")}, nil
+}
+
+// PublicKey returns Bob's public key.
+func (b *DHBob) PublicKey() *big.Int {
+	return b.priv.Y
+}
+
+// Respond takes Alice's public key h and returns Bob's message and its MAC
+// under the shared secret h**x.
+func (b *DHBob) Respond(h *big.Int) ([]byte, []byte) {
+	return b.msg, DHMAC(b.priv.SharedSecret(h), b.msg)
+}
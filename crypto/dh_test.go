@@ -0,0 +1,52 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the group of challenge 57, p-1 = j*q
+var (
+	testDHGroup = DHGroup{
+		P: fromDecimal("7199773997391911030609999317773941274322764333428698921736339643928346453700085358802973900485592910475480089726140708102474957429903531369589969318716771"),
+		G: fromDecimal("4565356397095740655436854503483826832136106141639563487732438195343690437606117828318042418238184896212352329118608100083187535033402010599512641674644143"),
+		Q: fromDecimal("236234353446506858198510045061214171961"),
+	}
+	testDHJ = fromDecimal("30477252323177606811760882179058908038824640750610513771646768011063128035873508507547741559514324673960576895059570")
+)
+
+func fromDecimal(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("Error: bad decimal number " + s)
+	}
+	return n
+}
+
+func TestDHGroup(t *testing.T) {
+	assert.NoError(t, testDHGroup.Validate())
+	pMinusOne := new(big.Int).Sub(testDHGroup.P, bigOne)
+	assert.Equal(t, pMinusOne, new(big.Int).Mul(testDHJ, testDHGroup.Q))
+
+	bad := testDHGroup
+	bad.G = big.NewInt(2)
+	assert.Error(t, bad.Validate())
+	bad.G = big.NewInt(1)
+	assert.Error(t, bad.Validate())
+}
+
+func TestDHSharedSecret(t *testing.T) {
+	alice, err := GenerateDHKey(testDHGroup)
+	assert.NoError(t, err)
+	bob, err := GenerateDHKey(testDHGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, alice.SharedSecret(bob.Y), bob.SharedSecret(alice.Y))
+	assert.True(t, alice.X.Cmp(testDHGroup.Q) < 0)
+
+	b, err := NewDHBob(testDHGroup)
+	assert.NoError(t, err)
+	msg, mac := b.Respond(alice.Y)
+	assert.Equal(t, DHMAC(alice.SharedSecret(b.PublicKey()), msg), mac)
+}
@@ -0,0 +1,262 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"math"
+	"math/big"
+)
+
+// ErrNoDiscreteLog is returned when a discrete log search finds nothing in
+// its interval.
+var ErrNoDiscreteLog = errors.New("no discrete log in the interval")
+
+// BabyStepGiantStep finds x in [a, b] with g**x = y mod p in about
+// sqrt(b-a) multiplications and as much memory.
+func BabyStepGiantStep(p, g, y, a, b *big.Int) (*big.Int, error) {
+	width := new(big.Int).Sub(b, a)
+	if width.Sign() < 0 {
+		return nil, errors.New("empty interval")
+	}
+	m := new(big.Int).Sqrt(width)
+	m.Add(m, bigOne)
+
+	// baby steps g**j for j < m
+	babies := make(map[string]int64)
+	step := big.NewInt(1)
+	for j := int64(0); big.NewInt(j).Cmp(m) < 0; j++ {
+		if _, ok := babies[string(step.Bytes())]; !ok {
+			babies[string(step.Bytes())] = j
+		}
+		step = new(big.Int).Mul(step, g)
+		step.Mod(step, p)
+	}
+
+	// giant steps y * g**-a * g**(-m*i)
+	gInv := new(big.Int).ModInverse(g, p)
+	if gInv == nil {
+		return nil, errors.New("g isn't invertible mod p")
+	}
+	giant := new(big.Int).Exp(gInv, m, p)
+	gamma := new(big.Int).Exp(gInv, a, p)
+	gamma.Mul(gamma, y).Mod(gamma, p)
+	for i := int64(0); big.NewInt(i).Cmp(m) <= 0; i++ {
+		if j, ok := babies[string(gamma.Bytes())]; ok {
+			x := new(big.Int).Mul(big.NewInt(i), m)
+			x.Add(x, big.NewInt(j)).Add(x, a)
+			if x.Cmp(b) <= 0 {
+				return x, nil
+			}
+		}
+		gamma.Mul(gamma, giant).Mod(gamma, p)
+	}
+	return nil, ErrNoDiscreteLog
+}
+
+// KangarooParams is the pseudorandom walk of Kangaroo: from an element y the
+// kangaroos jump by Jumps[Index(y)] in the exponent.
+type KangarooParams struct {
+	Jumps []*big.Int
+	Index func(y *big.Int) int
+	// TameJumps is how far the tame kangaroo goes to set the trap.
+	TameJumps int
+	// WildTries is how many wild kangaroos are sent before giving up.
+	WildTries int
+}
+
+// maxTameJumps bounds the walk of the tame kangaroo, so that it fits an int
+// anywhere. Intervals that need more are out of reach anyway.
+const maxTameJumps = math.MaxInt32
+
+// mask64 keeps the low 64 bits of a number.
+var mask64 = new(big.Int).SetUint64(math.MaxUint64)
+
+// NewKangarooParams makes the usual walk for the interval [a, b]: jumps of
+// 2**i for i < k picked by y mod k, with k chosen so the mean jump is about
+// sqrt(b-a)/2, and a tame kangaroo making 4 times as many jumps as the mean
+// jump. It fails for intervals wider than about 2**60, whose tame kangaroo
+// would jump more than maxTameJumps times.
+func NewKangarooParams(a, b *big.Int) (*KangarooParams, error) {
+	width := new(big.Int).Sub(b, a)
+	if width.Sign() < 0 {
+		return nil, errors.New("empty interval")
+	}
+	half := new(big.Int).Sqrt(width)
+	half.Rsh(half, 1)
+
+	k := 1
+	sum := big.NewInt(1)
+	// mean of 2**i for i < k is (2**k - 1)/k
+	for new(big.Int).Div(sum, big.NewInt(int64(k))).Cmp(half) < 0 {
+		k++
+		sum.Lsh(sum, 1).Add(sum, bigOne)
+	}
+	// 4 times the mean jump
+	tame := new(big.Int).Div(sum, big.NewInt(int64(k)))
+	tame.Lsh(tame, 2)
+	if tame.Cmp(big.NewInt(maxTameJumps)) > 0 {
+		return nil, errors.New("interval too wide for the kangaroos")
+	}
+	params := &KangarooParams{
+		Index: func(y *big.Int) int {
+			return int(new(big.Int).And(y, mask64).Uint64() % uint64(k))
+		},
+		WildTries: 8,
+	}
+	for i := 0; i < k; i++ {
+		params.Jumps = append(params.Jumps, new(big.Int).Lsh(bigOne, uint(i)))
+	}
+	params.TameJumps = int(tame.Int64())
+	if params.TameJumps < 4 {
+		params.TameJumps = 4
+	}
+	return params, nil
+}
+
+// KangarooGroup is a cyclic group the kangaroos can walk in, generated by
+// some g. Elements are opaque to the walk.
+type KangarooGroup interface {
+	// Exp returns g**x.
+	Exp(x *big.Int) interface{}
+	// Mul returns the product of u and v.
+	Mul(u, v interface{}) interface{}
+	// Equal reports whether u and v are the same element.
+	Equal(u, v interface{}) bool
+	// Label maps u to the integer KangarooParams.Index picks a jump from.
+	Label(u interface{}) *big.Int
+}
+
+// modPGroup is the group generated by g mod p.
+type modPGroup struct {
+	p, g *big.Int
+}
+
+func (m modPGroup) Exp(x *big.Int) interface{} {
+	return new(big.Int).Exp(m.g, x, m.p)
+}
+
+func (m modPGroup) Mul(u, v interface{}) interface{} {
+	z := new(big.Int).Mul(u.(*big.Int), v.(*big.Int))
+	return z.Mod(z, m.p)
+}
+
+func (m modPGroup) Equal(u, v interface{}) bool {
+	return u.(*big.Int).Cmp(v.(*big.Int)) == 0
+}
+
+func (m modPGroup) Label(u interface{}) *big.Int {
+	return u.(*big.Int)
+}
+
+// Kangaroo finds x in [a, b] with g**x = y mod p with Pollard's lambda
+// method, in about sqrt(b-a) multiplications and constant memory.
+// Link: https://cryptopals.com/sets/8/challenges/58
+func Kangaroo(p, g, y, a, b *big.Int, params *KangarooParams) (*big.Int, error) {
+	return KangarooIn(modPGroup{p, g}, y, a, b, params)
+}
+
+// KangarooIn finds x in [a, b] with g**x = y in group. A tame kangaroo
+// starts at g**b and leaves a trap where it stops, then a wild one starts at
+// y; once their paths meet they stay together, and the wild one falls into
+// the trap. A wild kangaroo may miss the trap, then another one starts a bit
+// further from y.
+func KangarooIn(group KangarooGroup, y interface{}, a, b *big.Int, params *KangarooParams) (*big.Int, error) {
+	if params == nil {
+		var err error
+		if params, err = NewKangarooParams(a, b); err != nil {
+			return nil, err
+		}
+	}
+	index := func(u interface{}) int {
+		return params.Index(group.Label(u))
+	}
+	steps := make([]interface{}, len(params.Jumps))
+	for i, jump := range params.Jumps {
+		steps[i] = group.Exp(jump)
+	}
+
+	// the tame kangaroo
+	xT := new(big.Int)
+	yT := group.Exp(b)
+	for i := 0; i < params.TameJumps; i++ {
+		j := index(yT)
+		xT.Add(xT, params.Jumps[j])
+		yT = group.Mul(yT, steps[j])
+	}
+
+	// the wild kangaroos give up once they've passed the trap
+	limit := new(big.Int).Sub(b, a)
+	limit.Add(limit, xT)
+	for try := 0; try < params.WildTries; try++ {
+		// the first one starts at y, the others at y * g**offset
+		offset := new(big.Int)
+		if try > 0 {
+			var err error
+			if offset, err = rand.Int(rand.Reader, big.NewInt(int64(params.TameJumps))); err != nil {
+				return nil, err
+			}
+		}
+		xW := new(big.Int).Set(offset)
+		yW := group.Mul(y, group.Exp(offset))
+		for xW.Cmp(limit) <= 0 {
+			if group.Equal(yW, yT) {
+				// g**(x + xW) = g**(b + xT), unless y has no log in
+				// [a, b] and the paths met anyway
+				x := new(big.Int).Add(b, xT)
+				x.Sub(x, xW)
+				if x.Cmp(a) >= 0 && x.Cmp(b) <= 0 && group.Equal(group.Exp(x), y) {
+					return x, nil
+				}
+				break
+			}
+			j := index(yW)
+			xW.Add(xW, params.Jumps[j])
+			yW = group.Mul(yW, steps[j])
+		}
+	}
+	return nil, ErrNoDiscreteLog
+}
+
+// KangarooWithHint finds the x in [0, q) with y = g**x in group knowing
+// x = n mod r, from a subgroup confinement attack that couldn't cover all of
+// q. Writing x = n + m*r, y * g**-n = (g**r)**m and m is at most q/r, which
+// Kangaroo finds in about sqrt(q/r) steps.
+// Link: https://cryptopals.com/sets/8/challenges/58
+func KangarooWithHint(group DHGroup, y, n, r *big.Int) (*big.Int, error) {
+	n = new(big.Int).Mod(n, r)
+	// g has order q, so g**-n = g**(q-n)
+	shift := new(big.Int).Sub(group.Q, n)
+	yPrime := new(big.Int).Exp(group.G, shift, group.P)
+	yPrime.Mul(yPrime, y).Mod(yPrime, group.P)
+	gPrime := new(big.Int).Exp(group.G, r, group.P)
+	maxM := new(big.Int).Div(new(big.Int).Sub(group.Q, bigOne), r)
+
+	m, err := Kangaroo(group.P, gPrime, yPrime, big.NewInt(0), maxM, nil)
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int).Mul(m, r)
+	return x.Add(x, n), nil
+}
+
+// PohligHellman finds x with g**x = y mod p when p-1 is the product of the
+// distinct primes in factors. x mod r comes from the discrete log of
+// y**((p-1)/r) to the base g**((p-1)/r), in the subgroup of order r, and the
+// residues are combined with the CRT. It returns x mod p-1; x is only
+// unique if g generates the whole group.
+func PohligHellman(p, g, y *big.Int, factors []*big.Int) (*big.Int, error) {
+	pMinusOne := new(big.Int).Sub(p, bigOne)
+	residues := make([]*big.Int, len(factors))
+	for i, r := range factors {
+		cofactor := new(big.Int).Div(pMinusOne, r)
+		gr := new(big.Int).Exp(g, cofactor, p)
+		yr := new(big.Int).Exp(y, cofactor, p)
+		x, err := BabyStepGiantStep(p, gr, yr, big.NewInt(0), new(big.Int).Sub(r, bigOne))
+		if err != nil {
+			return nil, err
+		}
+		residues[i] = x
+	}
+	x, _, err := CRT(residues, factors)
+	return x, err
+}
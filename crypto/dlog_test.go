@@ -0,0 +1,121 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the group of challenge 58, p-1 = j*q
+var (
+	testKangarooGroup = DHGroup{
+		P: fromDecimal("11470374874925275658116663507232161402086650258453896274534991676898999262641581519101074740642369848233294239851519212341844337347119899874391456329785623"),
+		G: fromDecimal("622952335333961296978159266084741085889881358738459939978290179936063635566740258555167783009058567397963466103140082647486611657350811560630587013183357"),
+		Q: fromDecimal("335062023296420808191071248367701059461"),
+	}
+	testKangarooJ = fromDecimal("34233586850807404623475048381328686211071196701374230492615844865929237417097514638999377942356150481334217896204702")
+)
+
+func TestKangarooGroup(t *testing.T) {
+	assert.NoError(t, testKangarooGroup.Validate())
+	pMinusOne := new(big.Int).Sub(testKangarooGroup.P, bigOne)
+	assert.Equal(t, pMinusOne, new(big.Int).Mul(testKangarooJ, testKangarooGroup.Q))
+}
+
+func TestBabyStepGiantStep(t *testing.T) {
+	g := testKangarooGroup
+	a, b := big.NewInt(1000), big.NewInt(1000+1<<20)
+	for _, x := range []*big.Int{a, b, big.NewInt(123456)} {
+		y := new(big.Int).Exp(g.G, x, g.P)
+		got, err := BabyStepGiantStep(g.P, g.G, y, a, b)
+		assert.NoError(t, err)
+		assert.Equal(t, x, got)
+	}
+	y := new(big.Int).Exp(g.G, big.NewInt(999), g.P)
+	_, err := BabyStepGiantStep(g.P, g.G, y, a, b)
+	assert.Equal(t, ErrNoDiscreteLog, err)
+}
+
+func TestKangaroo(t *testing.T) {
+	g := testKangarooGroup
+	a, b := big.NewInt(0), big.NewInt(1<<24)
+	for i := 0; i < 3; i++ {
+		x, err := rand.Int(rand.Reader, b)
+		assert.NoError(t, err)
+		y := new(big.Int).Exp(g.G, x, g.P)
+		got, err := Kangaroo(g.P, g.G, y, a, b, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, x, got)
+
+		// the same answer as baby-step giant-step
+		bsgs, err := BabyStepGiantStep(g.P, g.G, y, a, b)
+		assert.NoError(t, err)
+		assert.Equal(t, bsgs, got)
+	}
+
+	// a custom walk on a narrower interval
+	a, b = big.NewInt(5000), big.NewInt(6000)
+	params := &KangarooParams{
+		Jumps:     []*big.Int{big.NewInt(1), big.NewInt(3), big.NewInt(7), big.NewInt(11)},
+		Index:     func(y *big.Int) int { return int(y.Bit(3)<<1 | y.Bit(7)) },
+		TameJumps: 100,
+		WildTries: 50,
+	}
+	y := new(big.Int).Exp(g.G, big.NewInt(5678), g.P)
+	got, err := Kangaroo(g.P, g.G, y, a, b, params)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5678), got)
+
+	// a log outside the interval isn't returned
+	y = new(big.Int).Exp(g.G, big.NewInt(7000), g.P)
+	_, err = Kangaroo(g.P, g.G, y, a, b, params)
+	assert.Equal(t, ErrNoDiscreteLog, err)
+}
+
+func TestNewKangarooParams(t *testing.T) {
+	params, err := NewKangarooParams(big.NewInt(0), big.NewInt(1<<40))
+	assert.NoError(t, err)
+	assert.Equal(t, 24, len(params.Jumps))
+	assert.True(t, params.TameJumps > 1<<21)
+	// the walk only looks at the low bits of wide elements
+	wide := new(big.Int).Lsh(bigOne, 100)
+	assert.Equal(t, 5, params.Index(wide.Add(wide, big.NewInt(5))))
+
+	_, err = NewKangarooParams(big.NewInt(10), big.NewInt(9))
+	assert.Error(t, err)
+	_, err = NewKangarooParams(big.NewInt(0), new(big.Int).Lsh(bigOne, 80))
+	assert.Error(t, err)
+	_, err = Kangaroo(testKangarooGroup.P, testKangarooGroup.G, bigTwo, big.NewInt(0), new(big.Int).Lsh(bigOne, 80), nil)
+	assert.Error(t, err)
+}
+
+func TestKangarooWithHint(t *testing.T) {
+	g := testKangarooGroup
+	x, err := rand.Int(rand.Reader, g.Q)
+	assert.NoError(t, err)
+	y := new(big.Int).Exp(g.G, x, g.P)
+	// x mod r for r leaving 2**24 to search
+	r := new(big.Int).Rsh(g.Q, 24)
+	n := new(big.Int).Mod(x, r)
+	got, err := KangarooWithHint(g, y, n, r)
+	assert.NoError(t, err)
+	assert.Equal(t, x, got)
+	assert.Equal(t, new(big.Int).Mod(x, r), n)
+}
+
+func TestSubgroupConfinementKangaroo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the kangaroo walks an interval of 2**40")
+	}
+	g := testKangarooGroup
+	bob, err := NewDHBob(g)
+	assert.NoError(t, err)
+	n, r, err := SubgroupConfinementAttack(g, testKangarooJ, 1<<16, bob.Respond)
+	assert.NoError(t, err)
+	assert.True(t, r.Cmp(g.Q) < 0)
+	x, err := KangarooWithHint(g, bob.PublicKey(), n, r)
+	assert.NoError(t, err)
+	assert.Equal(t, bob.priv.X, x)
+}
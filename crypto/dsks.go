@@ -0,0 +1,163 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// smoothPrimeFactor is the size of the odd primes in p-1 for smoothPrime.
+const smoothPrimeFactor = 16
+
+// smoothPrimeFactors lists the primes of exactly smoothPrimeFactor bits.
+func smoothPrimeFactors() []*big.Int {
+	n := 1 << smoothPrimeFactor
+	composite := make([]bool, n)
+	var primes []*big.Int
+	for i := 2; i < n; i++ {
+		if composite[i] {
+			continue
+		}
+		for j := i * i; j < n; j += i {
+			composite[j] = true
+		}
+		if i >= n/2 {
+			primes = append(primes, big.NewInt(int64(i)))
+		}
+	}
+	return primes
+}
+
+// smoothPrime returns a prime p of the given size with its two top bits set
+// and p-1 = 2 * r1 * ... * rk for distinct primes ri of at most 16 bits, none
+// of them in used, along with the factors of p-1. With the top bits set the
+// product of two such primes always has the size of both.
+func smoothPrime(bits int, used map[string]bool) (*big.Int, []*big.Int, error) {
+	pool := smoothPrimeFactors()
+	// p-1 lands in [3 * 2**(bits-2), 2**bits)
+	low := new(big.Int).Lsh(big.NewInt(3), uint(bits-2))
+	high := new(big.Int).Lsh(bigOne, uint(bits))
+	for {
+		factors := []*big.Int{big.NewInt(2)}
+		seen := make(map[string]bool)
+		product := big.NewInt(2)
+		// at least 2**(bits-16), so the last factor has 16 bits at most
+		for product.BitLen() <= bits-smoothPrimeFactor {
+			i, err := rand.Int(rand.Reader, big.NewInt(int64(len(pool))))
+			if err != nil {
+				return nil, nil, err
+			}
+			r := pool[i.Int64()]
+			if used[r.String()] || seen[r.String()] {
+				continue
+			}
+			seen[r.String()] = true
+			factors = append(factors, r)
+			product.Mul(product, r)
+		}
+
+		// the last factor brings p in range
+		lo := ceilDiv(low, product)
+		hi := new(big.Int).Div(high, product)
+		width := new(big.Int).Sub(hi, lo)
+		if lo.Cmp(big.NewInt(3)) < 0 || width.Cmp(big.NewInt(64)) < 0 {
+			continue
+		}
+		for tries := 0; tries < 64; tries++ {
+			last, err := rand.Int(rand.Reader, width)
+			if err != nil {
+				return nil, nil, err
+			}
+			last.Add(last, lo)
+			if !last.ProbablyPrime(0) || used[last.String()] || seen[last.String()] {
+				continue
+			}
+			p := new(big.Int).Mul(product, last)
+			p.Add(p, bigOne)
+			if p.ProbablyPrime(20) {
+				return p, append(factors, last), nil
+			}
+		}
+	}
+}
+
+// isGenerator tells whether g generates the whole group mod p, where the
+// factors are the primes dividing p-1.
+func isGenerator(g, p *big.Int, factors []*big.Int) bool {
+	pMinusOne := new(big.Int).Sub(p, bigOne)
+	for _, r := range factors {
+		if new(big.Int).Exp(g, new(big.Int).Div(pMinusOne, r), p).Cmp(bigOne) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// RSAKeySelection makes another RSA public key, of the same size, under which
+// sig is also a valid PKCS#1 v1.5 signature of msg. The new modulus is the
+// product of primes p and q for which p-1 and q-1 are smooth, so discrete
+// logs are easy with PohligHellman: with ep = log_s(pad(m)) mod p-1 and
+// eq = log_s(pad(m)) mod q-1, the exponent e' = ep mod p-1 and
+// eq mod (q-1)/2 gives s**e' = pad(m) mod p*q. The factors of (q-1)/2 are
+// kept apart from those of p-1 so the moduli are coprime, and primes are
+// drawn until ep and eq agree mod 2.
+// Link: https://cryptopals.com/sets/8/challenges/61
+func RSAKeySelection(pub *RSAPublicKey, h HashAlgorithm, msg, sig []byte) (*RSAPublicKey, error) {
+	if !VerifyPKCS1v15(pub, h, msg, sig) {
+		return nil, errors.New("signature doesn't verify under the original key")
+	}
+	bits := pub.N.BitLen()
+	block, err := EncodePKCS1v15Signature(h, msg, pub.Size())
+	if err != nil {
+		return nil, err
+	}
+	m := new(big.Int).SetBytes(block)
+	s := new(big.Int).SetBytes(sig)
+
+	// s must generate both groups so that pad(m) has a log to its base
+	prime := func(bits int, used map[string]bool) (*big.Int, *big.Int, []*big.Int, error) {
+		for {
+			p, factors, err := smoothPrime(bits, used)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if !isGenerator(s, p, factors) {
+				continue
+			}
+			e, err := PohligHellman(p, s, m, factors)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			return p, e, factors, nil
+		}
+	}
+
+	// p must leave room for a q that takes p*q above s
+	var p, ep *big.Int
+	var pFactors []*big.Int
+	for p == nil || new(big.Int).Lsh(p, uint(bits-bits/2)).Cmp(s) <= 0 {
+		if p, ep, pFactors, err = prime(bits/2, nil); err != nil {
+			return nil, err
+		}
+	}
+	used := make(map[string]bool)
+	for _, r := range pFactors {
+		used[r.String()] = true
+	}
+	for {
+		q, eq, _, err := prime(bits-bits/2, used)
+		if err != nil {
+			return nil, err
+		}
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits || n.Cmp(s) <= 0 || ep.Bit(0) != eq.Bit(0) {
+			continue
+		}
+		halfQ := new(big.Int).Rsh(q, 1)
+		e, _, err := CRT([]*big.Int{ep, new(big.Int).Mod(eq, halfQ)}, []*big.Int{new(big.Int).Sub(p, bigOne), halfQ})
+		if err != nil {
+			return nil, err
+		}
+		return &RSAPublicKey{n, e}, nil
+	}
+}
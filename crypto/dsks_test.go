@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmoothPrime(t *testing.T) {
+	p, factors, err := smoothPrime(256, map[string]bool{"40009": true})
+	assert.NoError(t, err)
+	assert.Equal(t, 256, p.BitLen())
+	assert.True(t, p.ProbablyPrime(20))
+	product := big.NewInt(1)
+	for _, r := range factors {
+		assert.True(t, r.BitLen() <= smoothPrimeFactor)
+		assert.NotEqual(t, "40009", r.String())
+		product.Mul(product, r)
+	}
+	assert.Equal(t, new(big.Int).Sub(p, bigOne), product)
+}
+
+func TestPohligHellman(t *testing.T) {
+	p, factors, err := smoothPrime(256, nil)
+	assert.NoError(t, err)
+	g := big.NewInt(2)
+	for !isGenerator(g, p, factors) {
+		g.Add(g, bigOne)
+	}
+	x := new(big.Int).Rsh(p, 3)
+	y := new(big.Int).Exp(g, x, p)
+	got, err := PohligHellman(p, g, y, factors)
+	assert.NoError(t, err)
+	assert.Equal(t, x, got)
+}
+
+func TestRSAKeySelection(t *testing.T) {
+	priv, err := GenerateRSAKey(1024, 65537)
+	assert.NoError(t, err)
+	msg := []byte("I owe you nothing")
+	sig, err := SignPKCS1v15(priv, SHA256, msg)
+	assert.NoError(t, err)
+
+	forged, err := RSAKeySelection(&priv.RSAPublicKey, SHA256, msg, sig)
+	assert.NoError(t, err)
+	assert.NotEqual(t, priv.N, forged.N)
+	assert.Equal(t, priv.Size(), forged.Size())
+	assert.True(t, VerifyPKCS1v15(forged, SHA256, msg, sig))
+	assert.False(t, VerifyPKCS1v15(forged, SHA256, []byte("I owe you everything"), sig))
+
+	_, err = RSAKeySelection(&priv.RSAPublicKey, SHA256, []byte("I owe you everything"), sig)
+	assert.Error(t, err)
+}
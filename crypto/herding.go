@@ -0,0 +1,194 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Diamond is the diamond structure of the herding attack: 2**K leaf states
+// that are paired up by collisions, level after level, down to a single Root
+// state. From any leaf there's a path of K blocks to the root.
+type Diamond struct {
+	K int
+	// Levels[0] holds the 2**K leaves, Levels[K] the root. The node i of
+	// Levels[l] goes to node i/2 of Levels[l+1] with the block Blocks[l][i].
+	Levels [][][]byte
+	Blocks [][][]byte
+}
+
+// Root returns the state all paths of the diamond lead to.
+func (d *Diamond) Root() []byte {
+	return d.Levels[d.K][0]
+}
+
+// BuildDiamond builds a diamond structure with 2**k random leaves. Every
+// level needs one collision per pair of states, and the pairs are spread
+// over the workers.
+// Link: https://cryptopals.com/sets/7/challenges/54
+func (h *ToyHash) BuildDiamond(k, workers int) (*Diamond, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	d := &Diamond{K: k, Levels: make([][][]byte, k+1), Blocks: make([][][]byte, k)}
+
+	// random distinct leaves
+	seen := make(map[string]bool)
+	for len(d.Levels[0]) < 1<<uint(k) {
+		leaf := make([]byte, h.Size)
+		if _, err := rand.Read(leaf); err != nil {
+			return nil, err
+		}
+		if !seen[string(leaf)] {
+			seen[string(leaf)] = true
+			d.Levels[0] = append(d.Levels[0], leaf)
+		}
+	}
+
+	for l := 0; l < k; l++ {
+		states := d.Levels[l]
+		d.Blocks[l] = make([][]byte, len(states))
+		d.Levels[l+1] = make([][]byte, len(states)/2)
+
+		pairs := make(chan int)
+		errs := make(chan error, workers)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var failed error
+				for i := range pairs {
+					if failed != nil {
+						// keep draining so the sender doesn't block
+						continue
+					}
+					b1, b2, next, err := h.collideStates(states[2*i], states[2*i+1])
+					if err != nil {
+						failed = err
+						errs <- err
+						continue
+					}
+					// every pair has its own slots, no locking needed
+					d.Blocks[l][2*i], d.Blocks[l][2*i+1] = b1, b2
+					d.Levels[l+1][i] = next
+				}
+			}()
+		}
+		for i := 0; i < len(states)/2; i++ {
+			pairs <- i
+		}
+		close(pairs)
+		wg.Wait()
+		close(errs)
+		if err := <-errs; err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Path returns the K blocks leading from leaf i to the root.
+func (d *Diamond) Path(i int) []byte {
+	var path []byte
+	for l := 0; l < d.K; l++ {
+		path = append(path, d.Blocks[l][i]...)
+		i /= 2
+	}
+	return path
+}
+
+// Save writes the diamond with encoding/gob so it can be reused.
+func (d *Diamond) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(d)
+}
+
+// maxDiamondK bounds the size of a loaded diamond, far beyond what can be
+// built in practice.
+const maxDiamondK = 30
+
+// LoadDiamond reads a diamond written by Save, and checks its shape so that
+// Root, Path and Herd can't index out of range.
+func LoadDiamond(r io.Reader) (*Diamond, error) {
+	d := &Diamond{}
+	if err := gob.NewDecoder(r).Decode(d); err != nil {
+		return nil, err
+	}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// validate checks that level l has 2**(K-l) states of the same size and
+// Blocks[l] one block per state of level l.
+func (d *Diamond) validate() error {
+	if d.K < 0 || d.K > maxDiamondK {
+		return fmt.Errorf("malformed diamond structure: K = %d", d.K)
+	}
+	if len(d.Levels) != d.K+1 || len(d.Blocks) != d.K {
+		return errors.New("malformed diamond structure: wrong number of levels")
+	}
+	for l, states := range d.Levels {
+		if len(states) != 1<<uint(d.K-l) {
+			return fmt.Errorf("malformed diamond structure: level %d has %d states", l, len(states))
+		}
+	}
+	size := len(d.Levels[d.K][0])
+	for l, states := range d.Levels {
+		for _, state := range states {
+			if len(state) == 0 || len(state) != size {
+				return fmt.Errorf("malformed diamond structure: state of %d bytes at level %d", len(state), l)
+			}
+		}
+	}
+	for l, blocks := range d.Blocks {
+		if len(blocks) != len(d.Levels[l]) {
+			return fmt.Errorf("malformed diamond structure: level %d has %d blocks", l, len(blocks))
+		}
+		for _, block := range blocks {
+			if len(block) != ToyHashBlockSize {
+				return fmt.Errorf("malformed diamond structure: block of %d bytes at level %d", len(block), l)
+			}
+		}
+	}
+	return nil
+}
+
+// Prediction is the hash committed to in advance: the hash of any message
+// of msgBlocks blocks that ends at the root of the diamond.
+func (h *ToyHash) Prediction(d *Diamond, msgBlocks int) []byte {
+	return h.Chain(d.Root(), h.Pad(msgBlocks*ToyHashBlockSize))
+}
+
+// Herd makes a message that starts with prefix and hashes to the prediction
+// for prefixBlocks + 1 + K blocks. The prefix is padded with spaces to whole
+// blocks; a linking block then takes its state to one of the leaves, which
+// takes about 2**(b-K) compressions, and the path of the leaf leads to the
+// root.
+// Link: https://cryptopals.com/sets/7/challenges/54
+func (h *ToyHash) Herd(d *Diamond, prefix []byte) ([]byte, error) {
+	msg := append([]byte{}, prefix...)
+	for len(msg)%ToyHashBlockSize != 0 {
+		msg = append(msg, ' ')
+	}
+	leaves := make(map[string]int)
+	for i, leaf := range d.Levels[0] {
+		leaves[string(leaf)] = i
+	}
+
+	state := h.Chain(h.IV, msg)
+	link := make([]byte, ToyHashBlockSize)
+	for tries := 0; tries < 1<<uint(8*h.Size+4); tries++ {
+		if _, err := rand.Read(link); err != nil {
+			return nil, err
+		}
+		if i, ok := leaves[string(h.Compress(state, link))]; ok {
+			return append(append(msg, link...), d.Path(i)...), nil
+		}
+	}
+	return nil, fmt.Errorf("no linking block into the %d leaves", len(leaves))
+}
@@ -0,0 +1,74 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDiamond(t *testing.T) {
+	h := NewToyHash(2)
+	d, err := h.BuildDiamond(6, 4)
+	assert.NoError(t, err)
+	assert.Len(t, d.Levels[0], 64)
+	assert.Len(t, d.Levels[6], 1)
+	for i, leaf := range d.Levels[0] {
+		assert.Equal(t, d.Root(), h.Chain(leaf, d.Path(i)))
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, d.Save(&buf))
+	loaded, err := LoadDiamond(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, d, loaded)
+
+	_, err = LoadDiamond(bytes.NewBufferString("not a diamond"))
+	assert.Error(t, err)
+
+	// truncated or edited diamonds are rejected rather than panicking later
+	for name, edit := range map[string]func(d *Diamond){
+		"negative K":      func(d *Diamond) { d.K = -1 },
+		"missing level":   func(d *Diamond) { d.Levels = d.Levels[:d.K] },
+		"short level":     func(d *Diamond) { d.Levels[3] = d.Levels[3][:5] },
+		"empty root":      func(d *Diamond) { d.Levels[d.K] = [][]byte{{}} },
+		"short state":     func(d *Diamond) { d.Levels[2][1] = d.Levels[2][1][:1] },
+		"missing blocks":  func(d *Diamond) { d.Blocks[4] = d.Blocks[4][:1] },
+		"truncated block": func(d *Diamond) { d.Blocks[0][7] = d.Blocks[0][7][:3] },
+	} {
+		bad := &Diamond{K: d.K}
+		for _, states := range d.Levels {
+			bad.Levels = append(bad.Levels, append([][]byte{}, states...))
+		}
+		for _, blocks := range d.Blocks {
+			bad.Blocks = append(bad.Blocks, append([][]byte{}, blocks...))
+		}
+		edit(bad)
+		buf.Reset()
+		assert.NoError(t, bad.Save(&buf))
+		_, err = LoadDiamond(&buf)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestHerd(t *testing.T) {
+	h := NewToyHash(2)
+	k := 8
+	d, err := h.BuildDiamond(k, 8)
+	assert.NoError(t, err)
+
+	// commit to the hash of a message with 4 blocks of predictions
+	prediction := h.Prediction(d, 4+1+k)
+
+	// reload the structure, as if the season were over
+	var buf bytes.Buffer
+	assert.NoError(t, d.Save(&buf))
+	d, err = LoadDiamond(&buf)
+	assert.NoError(t, err)
+
+	prefix := []byte("Red Sox 4, Yankees 2; Cubs 7, Mets 1; Dodgers 3, Giants 0")
+	msg, err := h.Herd(d, prefix)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(msg, prefix))
+	assert.Equal(t, prediction, h.Sum(msg))
+}
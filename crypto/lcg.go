@@ -0,0 +1,233 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"gosano/lattice"
+)
+
+// PRNG is a pseudorandom generator of bytes, like LFSR and LCG.
+type PRNG interface {
+	Byte() byte
+}
+
+// PRNGXORKeyStream XORs src into dst, which may be src itself, with the next
+// bytes of the PRNG.
+func PRNGXORKeyStream(prng PRNG, dst, src []byte) {
+	for i, b := range src {
+		dst[i] = b ^ prng.Byte()
+	}
+}
+
+// LCG is a linear congruential generator x = A*x + C mod M that outputs the
+// top bits of each new state, x >> Shift.
+type LCG struct {
+	M, A, C *big.Int
+	Shift   uint
+	state   *big.Int
+}
+
+// NewLCG makes an LCG with seed as its state.
+func NewLCG(m, a, c *big.Int, shift uint, seed *big.Int) (*LCG, error) {
+	if m.Cmp(bigTwo) < 0 {
+		return nil, errors.New("LCG modulus must be at least 2")
+	}
+	if shift >= uint(m.BitLen()) {
+		return nil, fmt.Errorf("shift of %d leaves nothing of a %d-bit state", shift, m.BitLen())
+	}
+	return &LCG{
+		M:     new(big.Int).Set(m),
+		A:     new(big.Int).Mod(a, m),
+		C:     new(big.Int).Mod(c, m),
+		Shift: shift,
+		state: new(big.Int).Mod(seed, m),
+	}, nil
+}
+
+// State returns the current state.
+func (g *LCG) State() *big.Int {
+	return new(big.Int).Set(g.state)
+}
+
+// Step moves to the next state and returns its output.
+func (g *LCG) Step() *big.Int {
+	g.state.Mul(g.state, g.A).Add(g.state, g.C).Mod(g.state, g.M)
+	return new(big.Int).Rsh(g.state, g.Shift)
+}
+
+// mask8 keeps the low byte of an output.
+var mask8 = big.NewInt(0xff)
+
+// Byte returns the low 8 bits of the next output, which makes the LCG a
+// PRNG.
+func (g *LCG) Byte() byte {
+	return byte(new(big.Int).And(g.Step(), mask8).Uint64())
+}
+
+// ErrAmbiguousLCG is returned by RecoverLCG when the outputs fit LCGs with
+// different moduli, which more outputs tell apart.
+var ErrAmbiguousLCG = errors.New("the outputs fit more than one LCG modulus")
+
+// minLCGOutputs is the fewest outputs that give RecoverLCG two multiples of
+// the modulus.
+const minLCGOutputs = 5
+
+// maxLCGCofactor bounds the search of RecoverLCG for a smaller modulus.
+const maxLCGCofactor = 1 << 16
+
+// RecoverLCG finds the modulus, multiplier and increment of an LCG from
+// consecutive full outputs, and returns it with the last one as its state so
+// it predicts the next ones. With t[n] = x[n+1] - x[n], which is
+// A**n * t[0] mod M, t[n+2]*t[n] - t[n+1]**2 is a multiple of M, and the gcd
+// of enough of them is M itself. Then A = t[1] / t[0] and C = x[1] - A*x[0].
+// If the gcd has a divisor that the outputs fit as well, it returns
+// ErrAmbiguousLCG.
+func RecoverLCG(outputs []*big.Int) (*LCG, error) {
+	if len(outputs) < minLCGOutputs {
+		return nil, fmt.Errorf("need at least %d outputs", minLCGOutputs)
+	}
+	t := make([]*big.Int, len(outputs)-1)
+	for i := range t {
+		t[i] = new(big.Int).Sub(outputs[i+1], outputs[i])
+	}
+	m := new(big.Int)
+	for i := 0; i+2 < len(t); i++ {
+		u := new(big.Int).Mul(t[i+2], t[i])
+		u.Sub(u, new(big.Int).Mul(t[i+1], t[i+1]))
+		m.GCD(nil, nil, m, u.Abs(u))
+	}
+	if m.Sign() == 0 {
+		// an LCG over the integers, which fits any modulus
+		return nil, ErrAmbiguousLCG
+	}
+	largest := new(big.Int)
+	for _, x := range outputs {
+		if m.Cmp(x) <= 0 {
+			return nil, errors.New("the outputs don't come from an LCG")
+		}
+		if x.Cmp(largest) > 0 {
+			largest = x
+		}
+	}
+	// every modulus the outputs fit divides m and is above the largest
+	// output, so m is the only one unless m/p is above it for some p
+	for p := int64(2); new(big.Int).Mul(largest, big.NewInt(p)).Cmp(m) < 0; p++ {
+		if p > maxLCGCofactor || new(big.Int).Mod(m, big.NewInt(p)).Sign() == 0 {
+			return nil, ErrAmbiguousLCG
+		}
+	}
+
+	// A = t[i+1] / t[i] for any invertible t[i]
+	var a *big.Int
+	for i := 0; i+1 < len(t) && a == nil; i++ {
+		inv := new(big.Int).Mod(t[i], m)
+		if inv.ModInverse(inv, m) != nil {
+			a = inv.Mul(inv, t[i+1]).Mod(inv, m)
+		}
+	}
+	if a == nil {
+		return nil, errors.New("no difference of outputs is invertible")
+	}
+	c := new(big.Int).Mul(a, outputs[0])
+	c.Sub(outputs[1], c).Mod(c, m)
+
+	g, err := NewLCG(m, a, c, 0, outputs[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range outputs[1:] {
+		if g.Step().Cmp(x) != 0 {
+			return nil, errors.New("the outputs don't come from an LCG, or too few to find its modulus")
+		}
+	}
+	return g, nil
+}
+
+// RecoverTruncatedLCG recovers the state of an LCG with known parameters
+// from consecutive outputs that only have the top bits of the states, and
+// returns it with the last observed state so it predicts the next outputs.
+//
+// With the first observed state x, the states minus the increments
+// c[i] = C*(A**i - 1)/(A - 1) are A**i * x mod M: a point w of the lattice
+// spanned by (1, A, ..., A**(n-1)) and M*e_i. The outputs give an
+// approximation t of w, off by the dropped low bits e, which are short, so
+// (-e, K) shows up when LLL reduces the lattice with (t, K) embedded.
+// It usually needs a few more outputs than M.BitLen() / (M.BitLen() - Shift).
+func RecoverTruncatedLCG(m, a, c *big.Int, shift uint, outputs []*big.Int) (*LCG, error) {
+	n := len(outputs)
+	if n < 2 {
+		return nil, errors.New("need at least 2 outputs")
+	}
+	// t = 2**shift * y - c[i] + 2**(shift-1), to center e around 0
+	half := big.NewInt(0)
+	if shift > 0 {
+		half.Lsh(bigOne, shift-1)
+	}
+	t := make([]*big.Int, n)
+	ci := new(big.Int)
+	for i, y := range outputs {
+		t[i] = new(big.Int).Lsh(y, shift)
+		t[i].Sub(t[i], ci).Add(t[i], half).Mod(t[i], m)
+		ci.Mul(ci, a).Add(ci, c).Mod(ci, m)
+	}
+
+	k := new(big.Rat).SetInt(half)
+	if k.Sign() == 0 {
+		k.SetInt64(1)
+	}
+	basis := make([]lattice.Vector, n+1)
+	for i := range basis {
+		basis[i] = make(lattice.Vector, n+1)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Rat)
+		}
+	}
+	power := big.NewInt(1)
+	for j := 0; j < n; j++ {
+		basis[0][j].SetInt(power)
+		power = new(big.Int).Mul(power, a)
+		power.Mod(power, m)
+		if j > 0 {
+			basis[j][j].SetInt(m)
+		}
+		basis[n][j].SetInt(t[j])
+	}
+	basis[n][n].Set(k)
+
+	reduced, err := lattice.LLL(basis, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range reduced {
+		if new(big.Rat).Abs(row[n]).Cmp(k) != 0 {
+			continue
+		}
+		// the row is +-(-e, K), and x = t[0] + e[0]
+		e := new(big.Int).Set(row[0].Num())
+		if row[n].Sign() > 0 {
+			e.Neg(e)
+		}
+		x := new(big.Int).Add(t[0], e)
+		x.Mod(x, m)
+		g, err := NewLCG(m, a, c, shift, x)
+		if err != nil {
+			return nil, err
+		}
+		if new(big.Int).Rsh(x, shift).Cmp(outputs[0]) != 0 {
+			continue
+		}
+		ok := true
+		for _, y := range outputs[1:] {
+			if g.Step().Cmp(y) != 0 {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return g, nil
+		}
+	}
+	return nil, errors.New("state not found in the reduced basis")
+}
@@ -0,0 +1,188 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Knuth's MMIX LCG, whose outputs are usually the top 32 bits
+var (
+	testLCGModulus    = new(big.Int).Lsh(bigOne, 64)
+	testLCGMultiplier = fromDecimal("6364136223846793005")
+	testLCGIncrement  = fromDecimal("1442695040888963407")
+)
+
+func TestLCG(t *testing.T) {
+	g, err := NewLCG(big.NewInt(16), big.NewInt(5), big.NewInt(3), 0, big.NewInt(7))
+	assert.NoError(t, err)
+	var outputs []int64
+	for i := 0; i < 4; i++ {
+		outputs = append(outputs, g.Step().Int64())
+	}
+	// 5*7+3 = 38 = 6, 5*6+3 = 33 = 1, 5*1+3 = 8, 5*8+3 = 43 = 11
+	assert.Equal(t, []int64{6, 1, 8, 11}, outputs)
+	assert.Equal(t, int64(11), g.State().Int64())
+
+	// outputs wider than 64 bits are cut to their low byte
+	m := new(big.Int).Lsh(bigOne, 96)
+	wide, err := NewLCG(m, bigOne, new(big.Int).Sub(m, bigOne), 0, bigOne)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0), wide.Byte())
+	assert.Equal(t, byte(0xff), wide.Byte())
+	assert.Equal(t, byte(0xfe), wide.Byte())
+
+	_, err = NewLCG(bigOne, bigOne, bigOne, 0, bigOne)
+	assert.Error(t, err)
+	_, err = NewLCG(big.NewInt(16), bigOne, bigOne, 5, bigOne)
+	assert.Error(t, err)
+}
+
+func TestPRNGXORKeyStream(t *testing.T) {
+	seed := big.NewInt(0x0123456789abcdef)
+	newPRNGs := []func() PRNG{
+		func() PRNG {
+			g, err := NewLCG(testLCGModulus, testLCGMultiplier, testLCGIncrement, 32, seed)
+			assert.NoError(t, err)
+			return g
+		},
+		func() PRNG {
+			l, err := NewLFSR(Fibonacci, 32, []uint{1, 2, 22, 32}, seed.Uint64())
+			assert.NoError(t, err)
+			return l
+		},
+	}
+	msg := []byte("We all live in a yellow submarine")
+	for _, newPRNG := range newPRNGs {
+		ciphertext := make([]byte, len(msg))
+		PRNGXORKeyStream(newPRNG(), ciphertext, msg)
+		assert.NotEqual(t, msg, ciphertext)
+		PRNGXORKeyStream(newPRNG(), ciphertext, ciphertext)
+		assert.Equal(t, msg, ciphertext)
+	}
+
+	// the keystream of an LCG is the low byte of its outputs
+	g := newPRNGs[0]().(*LCG)
+	keystream := make([]byte, 8)
+	PRNGXORKeyStream(g, keystream, keystream)
+	g2 := newPRNGs[0]().(*LCG)
+	for _, k := range keystream {
+		assert.Equal(t, byte(g2.Step().Uint64()), k)
+	}
+
+	// and that of an LFSR the same as its own XORKeyStream
+	l := newPRNGs[1]().(*LFSR)
+	l2 := newPRNGs[1]().(*LFSR)
+	ciphertext := make([]byte, len(msg))
+	PRNGXORKeyStream(l, ciphertext, msg)
+	expected := make([]byte, len(msg))
+	l2.XORKeyStream(expected, msg)
+	assert.Equal(t, expected, ciphertext)
+}
+
+func TestRecoverLCG(t *testing.T) {
+	m := fromDecimal("18446744073709551557") // the largest 64-bit prime
+	a, err := rand.Int(rand.Reader, m)
+	assert.NoError(t, err)
+	c, err := rand.Int(rand.Reader, m)
+	assert.NoError(t, err)
+	seed, err := rand.Int(rand.Reader, m)
+	assert.NoError(t, err)
+	for _, params := range [][3]*big.Int{
+		{m, a, c},
+		{new(big.Int).Lsh(bigOne, 31), big.NewInt(1103515245), big.NewInt(12345)},
+	} {
+		g, err := NewLCG(params[0], params[1], params[2], 0, seed)
+		assert.NoError(t, err)
+		var outputs []*big.Int
+		for i := 0; i < 24; i++ {
+			outputs = append(outputs, g.Step())
+		}
+
+		recovered, err := RecoverLCG(outputs)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, params[0], recovered.M)
+		assert.Equal(t, params[1], recovered.A)
+		assert.Equal(t, params[2], recovered.C)
+		for i := 0; i < 8; i++ {
+			assert.Equal(t, g.Step(), recovered.Step())
+		}
+	}
+
+	// with few outputs the LCG is either right or reported ambiguous, and
+	// fewer than minLCGOutputs are refused
+	for seed := int64(0); seed < 100; seed++ {
+		g, err := NewLCG(new(big.Int).Lsh(bigOne, 31), big.NewInt(1103515245), big.NewInt(12345), 0, big.NewInt(seed))
+		assert.NoError(t, err)
+		outputs := make([]*big.Int, minLCGOutputs+2)
+		for i := range outputs {
+			outputs[i] = g.Step()
+		}
+		recovered, err := RecoverLCG(outputs)
+		if err != ErrAmbiguousLCG && assert.NoError(t, err, "seed %d", seed) {
+			assert.Equal(t, g.M, recovered.M, "seed %d", seed)
+			assert.Equal(t, g.A, recovered.A, "seed %d", seed)
+			assert.Equal(t, g.C, recovered.C, "seed %d", seed)
+		}
+		_, err = RecoverLCG(outputs[:minLCGOutputs-1])
+		assert.Error(t, err)
+	}
+
+	// 0, 1, 3, 7, 15 fit x = 2x + 1 mod 2**k for every k >= 4
+	var powers []*big.Int
+	for _, x := range []int64{0, 1, 3, 7, 15} {
+		powers = append(powers, big.NewInt(x))
+	}
+	_, err = RecoverLCG(powers)
+	assert.Equal(t, ErrAmbiguousLCG, err)
+
+	_, err = RecoverLCG([]*big.Int{bigOne, bigTwo})
+	assert.Error(t, err)
+	var notLCG []*big.Int
+	for i := int64(0); i < 24; i++ {
+		notLCG = append(notLCG, big.NewInt(i*i*i%101))
+	}
+	_, err = RecoverLCG(notLCG)
+	assert.Error(t, err)
+}
+
+func TestRecoverTruncatedLCG(t *testing.T) {
+	seed, err := rand.Int(rand.Reader, testLCGModulus)
+	assert.NoError(t, err)
+	g, err := NewLCG(testLCGModulus, testLCGMultiplier, testLCGIncrement, 32, seed)
+	assert.NoError(t, err)
+	var outputs []*big.Int
+	for i := 0; i < 6; i++ {
+		outputs = append(outputs, g.Step())
+	}
+	recovered, err := RecoverTruncatedLCG(testLCGModulus, testLCGMultiplier, testLCGIncrement, 32, outputs)
+	assert.NoError(t, err)
+	if assert.NotNil(t, recovered) {
+		assert.Equal(t, g.State(), recovered.State())
+		for i := 0; i < 8; i++ {
+			assert.Equal(t, g.Step(), recovered.Step())
+		}
+	}
+
+	// 128-bit prime modulus keeping only the top 24 bits of each state
+	m := fromDecimal("340282366920938463463374607431768211297")
+	a, err := rand.Int(rand.Reader, m)
+	assert.NoError(t, err)
+	c, err := rand.Int(rand.Reader, m)
+	assert.NoError(t, err)
+	g, err = NewLCG(m, a, c, 104, seed)
+	assert.NoError(t, err)
+	outputs = outputs[:0]
+	for i := 0; i < 12; i++ {
+		outputs = append(outputs, g.Step())
+	}
+	recovered, err = RecoverTruncatedLCG(m, a, c, 104, outputs)
+	assert.NoError(t, err)
+	if assert.NotNil(t, recovered) {
+		assert.Equal(t, g.Step(), recovered.Step())
+	}
+}
@@ -0,0 +1,199 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// LFSRKind tells how an LFSR applies its feedback.
+type LFSRKind int
+
+const (
+	// Fibonacci LFSRs shift in the sum of the tapped bits.
+	Fibonacci LFSRKind = iota
+	// Galois LFSRs XOR the output bit into the tapped bits as they shift.
+	Galois
+)
+
+// LFSR is a linear feedback shift register of up to 64 bits over GF(2). Its
+// output satisfies s[n] = s[n-j1] + s[n-j2] + ... for its taps j, that is
+// the connection polynomial 1 + x**j1 + x**j2 + ..., whichever its kind.
+type LFSR struct {
+	kind   LFSRKind
+	length uint
+	mask   uint64
+	state  uint64
+}
+
+// NewLFSR makes an LFSR of the given length with the taps, which are in
+// [1, length]. The low length bits of seed are the initial state; for a
+// Fibonacci LFSR they're the first output bits, lowest first. An all-zero
+// state outputs zeros forever.
+func NewLFSR(kind LFSRKind, length uint, taps []uint, seed uint64) (*LFSR, error) {
+	if length < 1 || length > 64 {
+		return nil, fmt.Errorf("invalid LFSR length %d", length)
+	}
+	if kind != Fibonacci && kind != Galois {
+		return nil, fmt.Errorf("invalid LFSR kind %d", kind)
+	}
+	l := &LFSR{kind, length, 0, seed & lfsrMask(length)}
+	for _, j := range taps {
+		if j < 1 || j > length {
+			return nil, fmt.Errorf("LFSR tap %d out of [1, %d]", j, length)
+		}
+		l.mask ^= 1 << l.tapBit(j)
+	}
+	return l, nil
+}
+
+// tapBit returns the bit of the mask for tap j. A Fibonacci LFSR reads
+// s[n-j] at bit length-j of its state. A Galois LFSR toggling bit j-1 has
+// the characteristic polynomial x**length + ... + x**(length-j) + ..., so it
+// follows the same recurrence.
+func (l *LFSR) tapBit(j uint) uint {
+	if l.kind == Galois {
+		return j - 1
+	}
+	return l.length - j
+}
+
+// lfsrMask returns the mask of the low length bits.
+func lfsrMask(length uint) uint64 {
+	if length == 64 {
+		return ^uint64(0)
+	}
+	return 1<<length - 1
+}
+
+// Taps returns the taps of the LFSR in increasing order.
+func (l *LFSR) Taps() []uint {
+	var taps []uint
+	for j := uint(1); j <= l.length; j++ {
+		if l.mask>>l.tapBit(j)&1 == 1 {
+			taps = append(taps, j)
+		}
+	}
+	return taps
+}
+
+// Length returns the number of bits of the LFSR.
+func (l *LFSR) Length() uint {
+	return l.length
+}
+
+// Bit clocks the LFSR once and returns the output bit.
+func (l *LFSR) Bit() byte {
+	out := l.state & 1
+	switch l.kind {
+	case Fibonacci:
+		feedback := uint64(bits.OnesCount64(l.state&l.mask) & 1)
+		l.state = l.state>>1 | feedback<<(l.length-1)
+	case Galois:
+		l.state >>= 1
+		if out == 1 {
+			l.state ^= l.mask
+		}
+	}
+	return byte(out)
+}
+
+// Byte clocks the LFSR 8 times and packs the bits, the first one lowest.
+func (l *LFSR) Byte() byte {
+	var b byte
+	for i := uint(0); i < 8; i++ {
+		b |= l.Bit() << i
+	}
+	return b
+}
+
+// XORKeyStream XORs src with the next bytes of the keystream into dst, which
+// may be src itself.
+func (l *LFSR) XORKeyStream(dst, src []byte) {
+	PRNGXORKeyStream(l, dst, src)
+}
+
+// KeystreamBits unpacks keystream bytes into bits, lowest first in each
+// byte like LFSR.Byte.
+func KeystreamBits(keystream []byte) []byte {
+	s := make([]byte, 8*len(keystream))
+	for i, b := range keystream {
+		for j := uint(0); j < 8; j++ {
+			s[8*i+int(j)] = b >> j & 1
+		}
+	}
+	return s
+}
+
+// BerlekampMassey returns the linear complexity of a sequence of bits, the
+// length of the shortest LFSR that generates it, and that LFSR's taps. The
+// LFSR is unique once the sequence has at least twice as many bits as its
+// length.
+func BerlekampMassey(s []byte) (uint, []uint) {
+	// c is the current connection polynomial and b the one before the
+	// last length change, m steps ago
+	c := make([]byte, len(s)+1)
+	b := make([]byte, len(s)+1)
+	c[0], b[0] = 1, 1
+	length, m := 0, 1
+	for n := range s {
+		d := s[n]
+		for i := 1; i <= length; i++ {
+			d ^= c[i] & s[n-i]
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+		t := append([]byte{}, c...)
+		for i := 0; i+m < len(c); i++ {
+			c[i+m] ^= b[i]
+		}
+		if 2*length <= n {
+			length = n + 1 - length
+			b = t
+			m = 1
+		} else {
+			m++
+		}
+	}
+
+	var taps []uint
+	for j := 1; j <= length; j++ {
+		if c[j] == 1 {
+			taps = append(taps, uint(j))
+		}
+	}
+	return uint(length), taps
+}
+
+// RecoverLFSR finds the shortest LFSR that generates the keystream, from
+// known plaintext for instance, and returns it as a Fibonacci LFSR clocked
+// past the keystream, so that its output predicts what comes next. It needs
+// at least twice as many bits as the LFSR's length.
+func RecoverLFSR(keystream []byte) (*LFSR, error) {
+	s := KeystreamBits(keystream)
+	length, taps := BerlekampMassey(s)
+	if length == 0 {
+		return nil, errors.New("the keystream is all zeros")
+	}
+	if 2*int(length) > len(s) {
+		return nil, fmt.Errorf("%d bits of keystream can't pin down an LFSR of length %d", len(s), length)
+	}
+	if length > 64 {
+		return nil, fmt.Errorf("LFSR of length %d is too long", length)
+	}
+	// the first length bits are the initial state
+	var seed uint64
+	for i := uint(0); i < length; i++ {
+		seed |= uint64(s[i]) << i
+	}
+	l, err := NewLFSR(Fibonacci, length, taps, seed)
+	if err != nil {
+		return nil, err
+	}
+	for range s {
+		l.Bit()
+	}
+	return l, nil
+}
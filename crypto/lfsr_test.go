@@ -0,0 +1,104 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLFSR(t *testing.T) {
+	// s[n] = s[n-3] + s[n-4] has period 15 from any non-zero state
+	for _, kind := range []LFSRKind{Fibonacci, Galois} {
+		l, err := NewLFSR(kind, 4, []uint{3, 4}, 0b0001)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{3, 4}, l.Taps())
+		var s []byte
+		for i := 0; i < 30; i++ {
+			s = append(s, l.Bit())
+		}
+		assert.Equal(t, s[:15], s[15:], "kind %d", kind)
+		for n := 4; n < len(s); n++ {
+			assert.Equal(t, s[n-3]^s[n-4], s[n], "kind %d bit %d", kind, n)
+		}
+	}
+
+	l, err := NewLFSR(Fibonacci, 4, []uint{3, 4}, 0b1011)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 1, 0, 1}, []byte{l.Bit(), l.Bit(), l.Bit(), l.Bit()})
+
+	_, err = NewLFSR(Fibonacci, 65, nil, 1)
+	assert.Error(t, err)
+	_, err = NewLFSR(Galois, 8, []uint{9}, 1)
+	assert.Error(t, err)
+	_, err = NewLFSR(LFSRKind(2), 8, []uint{8}, 1)
+	assert.Error(t, err)
+}
+
+func TestLFSRXORKeyStream(t *testing.T) {
+	taps := []uint{64, 63, 61, 60}
+	enc, err := NewLFSR(Galois, 64, taps, 0xdeadbeefcafebabe)
+	assert.NoError(t, err)
+	dec, err := NewLFSR(Galois, 64, taps, 0xdeadbeefcafebabe)
+	assert.NoError(t, err)
+	msg := []byte("Ice, ice, baby, too cold")
+	ciphertext := make([]byte, len(msg))
+	enc.XORKeyStream(ciphertext, msg)
+	assert.NotEqual(t, msg, ciphertext)
+	dec.XORKeyStream(ciphertext, ciphertext)
+	assert.Equal(t, msg, ciphertext)
+}
+
+func TestBerlekampMassey(t *testing.T) {
+	length, taps := BerlekampMassey([]byte{1, 0, 1, 0, 1, 0, 1, 0})
+	assert.Equal(t, uint(2), length)
+	assert.Equal(t, []uint{2}, taps)
+
+	// nothing comes before the 1, so 1 + x**4 will do
+	length, taps = BerlekampMassey([]byte{0, 0, 0, 1})
+	assert.Equal(t, uint(4), length)
+	assert.Equal(t, []uint{4}, taps)
+
+	length, _ = BerlekampMassey(make([]byte, 10))
+	assert.Equal(t, uint(0), length)
+
+	for _, kind := range []LFSRKind{Fibonacci, Galois} {
+		l, err := NewLFSR(kind, 33, []uint{13, 33}, 0x1abcdef01)
+		assert.NoError(t, err)
+		var s []byte
+		for i := 0; i < 100; i++ {
+			s = append(s, l.Bit())
+		}
+		length, taps = BerlekampMassey(s)
+		assert.Equal(t, uint(33), length)
+		assert.Equal(t, []uint{13, 33}, taps)
+	}
+}
+
+func TestRecoverLFSR(t *testing.T) {
+	l, err := NewLFSR(Galois, 48, []uint{20, 28, 47, 48}, 0x123456789abc)
+	assert.NoError(t, err)
+	plaintext := []byte("known plaintext at the start, then the secret: the password is swordfish")
+	ciphertext := make([]byte, len(plaintext))
+	l.XORKeyStream(ciphertext, plaintext)
+
+	// 12 bytes of known plaintext give 96 bits, twice the length
+	known := 12
+	keystream := FixedXOR(plaintext[:known], ciphertext[:known])
+	recovered, err := RecoverLFSR(keystream)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(48), recovered.Length())
+	assert.Equal(t, []uint{20, 28, 47, 48}, recovered.Taps())
+	rest := make([]byte, len(ciphertext)-known)
+	recovered.XORKeyStream(rest, ciphertext[known:])
+	assert.Equal(t, plaintext[known:], rest)
+
+	// too little keystream gives a shorter LFSR that only fits what it saw
+	short, err := RecoverLFSR(keystream[:5])
+	assert.NoError(t, err)
+	assert.True(t, short.Length() < 48)
+	// seven zeros then a one need an LFSR of length 8
+	_, err = RecoverLFSR([]byte{0x80})
+	assert.Error(t, err)
+	_, err = RecoverLFSR(make([]byte, 8))
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// MD4State is the chaining state of MD4, the four words a, b, c, d.
+type MD4State [4]uint32
+
+// MD4IV is the initial MD4 state.
+var MD4IV = MD4State{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+
+// MD4 round constants and shift amounts, per RFC 1320.
+const (
+	md4Round2Constant = 0x5a827999
+	md4Round3Constant = 0x6ed9eba1
+)
+
+var (
+	md4Round1Shifts = [4]int{3, 7, 11, 19}
+	md4Round2Shifts = [4]int{3, 5, 9, 13}
+	md4Round3Shifts = [4]int{3, 9, 11, 15}
+	// message word order of rounds 2 and 3
+	md4Round2Order = [16]int{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}
+	md4Round3Order = [16]int{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+)
+
+// MD4F is the round 1 function, x ? y : z.
+func MD4F(x, y, z uint32) uint32 {
+	return x&y | ^x&z
+}
+
+// MD4G is the round 2 function, the majority of x, y and z.
+func MD4G(x, y, z uint32) uint32 {
+	return x&y | x&z | y&z
+}
+
+// MD4H is the round 3 function, the parity of x, y and z.
+func MD4H(x, y, z uint32) uint32 {
+	return x ^ y ^ z
+}
+
+// MD4Round1 is one step of round 1: (a + F(b, c, d) + x) <<< s.
+func MD4Round1(a, b, c, d, x uint32, s int) uint32 {
+	return bits.RotateLeft32(a+MD4F(b, c, d)+x, s)
+}
+
+// MD4Round2 is one step of round 2: (a + G(b, c, d) + x + 0x5a827999) <<< s.
+func MD4Round2(a, b, c, d, x uint32, s int) uint32 {
+	return bits.RotateLeft32(a+MD4G(b, c, d)+x+md4Round2Constant, s)
+}
+
+// MD4Round3 is one step of round 3: (a + H(b, c, d) + x + 0x6ed9eba1) <<< s.
+func MD4Round3(a, b, c, d, x uint32, s int) uint32 {
+	return bits.RotateLeft32(a+MD4H(b, c, d)+x+md4Round3Constant, s)
+}
+
+// MD4Words splits a 64-byte block into little-endian words.
+func MD4Words(block []byte) [16]uint32 {
+	var x [16]uint32
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(block[4*i:])
+	}
+	return x
+}
+
+// MD4Block joins the words back into a 64-byte block.
+func MD4Block(x [16]uint32) []byte {
+	block := make([]byte, 64)
+	for i, w := range x {
+		binary.LittleEndian.PutUint32(block[4*i:], w)
+	}
+	return block
+}
+
+// MD4Compress runs the 48 steps of MD4 on one block of words. Every step
+// updates one of a, d, c, b in turn.
+func MD4Compress(state MD4State, x [16]uint32) MD4State {
+	v := state
+	round := func(step func(a, b, c, d, x uint32, s int) uint32, order [16]int, shifts [4]int) {
+		for i := 0; i < 16; i++ {
+			// the word being updated goes a, d, c, b
+			j := (4 - i%4) % 4
+			v[j] = step(v[j], v[(j+1)%4], v[(j+2)%4], v[(j+3)%4], x[order[i]], shifts[i%4])
+		}
+	}
+	round(MD4Round1, [16]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, md4Round1Shifts)
+	round(MD4Round2, md4Round2Order, md4Round2Shifts)
+	round(MD4Round3, md4Round3Order, md4Round3Shifts)
+	for i := range v {
+		v[i] += state[i]
+	}
+	return v
+}
+
+// MD4Pad returns the MD4 padding for a message of msgLen bytes. It's MD
+// strengthening with a little-endian bit length.
+func MD4Pad(msgLen int) []byte {
+	n := 64 - (msgLen+9)%64
+	if n == 64 {
+		n = 0
+	}
+	pad := make([]byte, 1+n+8)
+	pad[0] = 0x80
+	binary.LittleEndian.PutUint64(pad[1+n:], uint64(msgLen)*8)
+	return pad
+}
+
+// MD4Sum computes the MD4 digest of msg.
+func MD4Sum(msg []byte) [16]byte {
+	padded := append(append([]byte{}, msg...), MD4Pad(len(msg))...)
+	state := MD4IV
+	for i := 0; i < len(padded); i += 64 {
+		state = MD4Compress(state, MD4Words(padded[i:i+64]))
+	}
+	var digest [16]byte
+	for i, w := range state {
+		binary.LittleEndian.PutUint32(digest[4*i:], w)
+	}
+	return digest
+}
@@ -0,0 +1,30 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMD4Sum(t *testing.T) {
+	// test vectors from RFC 1320
+	tests := map[string]string{
+		"":                           "31d6cfe0d16ae931b73c59d7e0c089c0",
+		"a":                          "bde52cb31de33e46245e05fbdbd6fb24",
+		"abc":                        "a448017aaf21d8525fc10ae87aa6729d",
+		"message digest":             "d9130a8164549fe818874806e1c7014b",
+		"abcdefghijklmnopqrstuvwxyz": "d79e1c308aa5bbcdeea8ed63df412da9",
+		"12345678901234567890123456789012345678901234567890123456789012345678901234567890": "e33b4ddc9c38f2199c3e7b164fcc0536",
+	}
+	for msg, want := range tests {
+		digest := MD4Sum([]byte(msg))
+		assert.Equal(t, want, hex.EncodeToString(digest[:]), msg)
+	}
+}
+
+func TestMD4Words(t *testing.T) {
+	block := []byte("YELLOW SUBMARINEYELLOW SUBMARINEYELLOW SUBMARINEYELLOW SUBMARINE")
+	assert.Equal(t, block, MD4Block(MD4Words(block)))
+	assert.Equal(t, uint32(0x4c4c4559), MD4Words(block)[0])
+}
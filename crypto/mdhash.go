@@ -0,0 +1,224 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ToyHashBlockSize is the message block size of ToyHash, one AES block.
+const ToyHashBlockSize = aes.BlockSize
+
+// ToyHash is a Merkle-Damgård hash with a tiny state, small enough for
+// generic collision attacks to run in seconds. The compression function
+// encrypts the message block with AES keyed by the state and keeps the first
+// Size bytes. The state is zero padded into the key, with the state size in
+// the last key byte so that hashes of different sizes are unrelated.
+type ToyHash struct {
+	Size  int
+	IV    []byte
+	calls int64
+}
+
+// NewToyHash makes a hash with a state of size bytes, 1 to 15.
+func NewToyHash(size int) *ToyHash {
+	if size < 1 || size >= aes.BlockSize {
+		panic(fmt.Sprintf("Error: toy hash of %d bytes", size))
+	}
+	iv := make([]byte, size)
+	for i := range iv {
+		iv[i] = byte(0x5a + i)
+	}
+	return &ToyHash{Size: size, IV: iv}
+}
+
+// Calls returns the number of times the compression function ran.
+func (h *ToyHash) Calls() int64 {
+	return atomic.LoadInt64(&h.calls)
+}
+
+// Compress is the compression function: the next state after one block.
+// It's safe for concurrent use.
+func (h *ToyHash) Compress(state, block []byte) []byte {
+	atomic.AddInt64(&h.calls, 1)
+	key := make([]byte, aes.BlockSize)
+	copy(key, state)
+	key[aes.BlockSize-1] = byte(h.Size)
+	cipher, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	out := make([]byte, aes.BlockSize)
+	cipher.Encrypt(out, block)
+	return out[:h.Size]
+}
+
+// Chain runs the compression function from state over a whole number of
+// blocks, without padding.
+func (h *ToyHash) Chain(state, msg []byte) []byte {
+	if len(msg)%ToyHashBlockSize != 0 {
+		panic(fmt.Sprintf("Error: chaining %d bytes, not whole blocks", len(msg)))
+	}
+	for i := 0; i < len(msg); i += ToyHashBlockSize {
+		state = h.Compress(state, msg[i:i+ToyHashBlockSize])
+	}
+	return state
+}
+
+// Pad returns the MD strengthening for a message of msgLen bytes: 0x80,
+// zeros, and the bit length as a 64-bit big-endian number.
+func (h *ToyHash) Pad(msgLen int) []byte {
+	n := ToyHashBlockSize - (msgLen+9)%ToyHashBlockSize
+	if n == ToyHashBlockSize {
+		n = 0
+	}
+	pad := make([]byte, 1+n+8)
+	pad[0] = 0x80
+	binary.BigEndian.PutUint64(pad[1+n:], uint64(msgLen)*8)
+	return pad
+}
+
+// Sum hashes msg with MD strengthening.
+func (h *ToyHash) Sum(msg []byte) []byte {
+	padded := append(append([]byte{}, msg...), h.Pad(len(msg))...)
+	return h.Chain(h.IV, padded)
+}
+
+// Collision is a pair of different blocks that take a state to the same Next.
+type Collision struct {
+	Block1, Block2 []byte
+	Next           []byte
+}
+
+// FindCollision finds two blocks colliding from state with the birthday
+// search, which takes about 2**(4*Size) compressions.
+func (h *ToyHash) FindCollision(state []byte) (Collision, error) {
+	seen := make(map[string][]byte)
+	for {
+		block := make([]byte, ToyHashBlockSize)
+		if _, err := rand.Read(block); err != nil {
+			return Collision{}, err
+		}
+		next := h.Compress(state, block)
+		other, ok := seen[string(next)]
+		if ok && !bytes.Equal(other, block) {
+			return Collision{other, block, next}, nil
+		}
+		seen[string(next)] = block
+	}
+}
+
+// Multicollision is Joux's construction: n collisions found one after the
+// other, each starting where the previous one ended. Picking either block at
+// every step gives 2**n messages of n blocks with the same hash, for n times
+// the price of a single collision.
+type Multicollision []Collision
+
+// JouxMulticollision finds a chain of n collisions starting at state.
+// Link: https://cryptopals.com/sets/7/challenges/52
+func (h *ToyHash) JouxMulticollision(state []byte, n int) (Multicollision, error) {
+	var mc Multicollision
+	for i := 0; i < n; i++ {
+		c, err := h.FindCollision(state)
+		if err != nil {
+			return nil, err
+		}
+		mc = append(mc, c)
+		state = c.Next
+	}
+	return mc, nil
+}
+
+// Message returns the colliding message picked by the bits of i: bit j
+// chooses between the two blocks of the j-th collision.
+func (mc Multicollision) Message(i int) []byte {
+	var msg []byte
+	for j, c := range mc {
+		if i>>uint(j)&1 == 0 {
+			msg = append(msg, c.Block1...)
+		} else {
+			msg = append(msg, c.Block2...)
+		}
+	}
+	return msg
+}
+
+// Messages returns all 2**n colliding messages.
+func (mc Multicollision) Messages() [][]byte {
+	messages := make([][]byte, 1<<uint(len(mc)))
+	for i := range messages {
+		messages[i] = mc.Message(i)
+	}
+	return messages
+}
+
+// CascadeStats reports the work done by CascadeCollision.
+type CascadeStats struct {
+	// FCalls and GCalls are the compression function calls of each hash.
+	FCalls, GCalls int64
+	// Collisions is the number of f collisions found, i.e. the length of the
+	// multicollision, and Candidates the number of messages tried on g.
+	Collisions int
+	Candidates int
+}
+
+// CascadeCollision finds a collision of f(x) || g(x), where f is the cheap
+// hash and g the costly one. A 2**(b/2)-way multicollision of f, with b the
+// bits of g, contains a collision of g by the birthday bound. The
+// multicollision is grown one collision at a time until g collides, and the
+// work stays around 2**(b/2) calls of g instead of 2**((a+b)/2) for a
+// generic attack on the concatenation.
+// Link: https://cryptopals.com/sets/7/challenges/52
+func CascadeCollision(f, g *ToyHash) ([]byte, []byte, CascadeStats, error) {
+	fCalls, gCalls := f.Calls(), g.Calls()
+	stats := CascadeStats{}
+	mc, err := f.JouxMulticollision(f.IV, 4*g.Size)
+	if err != nil {
+		return nil, nil, stats, err
+	}
+	// the g states of the messages of mc, indexed like mc.Message, carried
+	// from one round to the next so every round costs one block of g per
+	// message
+	states := [][]byte{g.IV}
+	for _, c := range mc[:len(mc)-1] {
+		states = g.extendStates(states, c)
+	}
+	for len(mc) <= 8*g.Size {
+		depth := len(mc) - 1
+		states = g.extendStates(states, mc[depth])
+		stats.Candidates += len(states)
+		seen := make(map[string]int, len(states))
+		for index, state := range states {
+			if other, ok := seen[string(state)]; ok {
+				stats.Collisions = len(mc)
+				stats.FCalls = f.Calls() - fCalls
+				stats.GCalls = g.Calls() - gCalls
+				return mc.Message(other), mc.Message(index), stats, nil
+			}
+			seen[string(state)] = index
+		}
+
+		c, err := f.FindCollision(mc[depth].Next)
+		if err != nil {
+			return nil, nil, stats, err
+		}
+		mc = append(mc, c)
+	}
+	return nil, nil, stats, errors.New("no collision in g")
+}
+
+// extendStates compresses both blocks of c from each of the states, which
+// are those of the messages of a multicollision, and returns the states of
+// the messages with c appended, in the order of Multicollision.Message.
+func (h *ToyHash) extendStates(states [][]byte, c Collision) [][]byte {
+	next := make([][]byte, 2*len(states))
+	for i, state := range states {
+		next[i] = h.Compress(state, c.Block1)
+		next[len(states)+i] = h.Compress(state, c.Block2)
+	}
+	return next
+}
@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToyHash(t *testing.T) {
+	h := NewToyHash(2)
+	assert.Len(t, h.Sum([]byte("hi mom")), 2)
+	assert.Equal(t, h.Sum([]byte("hi mom")), h.Sum([]byte("hi mom")))
+	assert.Equal(t, int64(3), h.Calls())
+
+	// the padding always ends on a block boundary
+	for n := 0; n < 40; n++ {
+		assert.Equal(t, 0, (n+len(h.Pad(n)))%ToyHashBlockSize, n)
+	}
+
+	// different sizes don't share their output bytes
+	g := NewToyHash(3)
+	state := []byte{1, 2, 3}
+	block := []byte("YELLOW SUBMARINE")
+	assert.NotEqual(t, h.Compress(state[:2], block), g.Compress(state, block)[:2])
+}
+
+func TestJouxMulticollision(t *testing.T) {
+	h := NewToyHash(2)
+	mc, err := h.JouxMulticollision(h.IV, 4)
+	assert.NoError(t, err)
+	messages := mc.Messages()
+	assert.Len(t, messages, 16)
+
+	want := h.Sum(messages[0])
+	distinct := make(map[string]bool)
+	for _, msg := range messages {
+		assert.Equal(t, want, h.Sum(msg))
+		distinct[string(msg)] = true
+	}
+	assert.Len(t, distinct, 16)
+}
+
+func TestCascadeCollision(t *testing.T) {
+	f := NewToyHash(2)
+	g := NewToyHash(3)
+	m1, m2, stats, err := CascadeCollision(f, g)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Equal(m1, m2))
+	assert.Equal(t, f.Sum(m1), f.Sum(m2))
+	assert.Equal(t, g.Sum(m1), g.Sum(m2))
+	assert.GreaterOrEqual(t, stats.Collisions, 12)
+	assert.Greater(t, stats.GCalls, int64(0))
+	t.Logf("%d f collisions, %d candidates, %d calls to f, %d calls to g",
+		stats.Collisions, stats.Candidates, stats.FCalls, stats.GCalls)
+}
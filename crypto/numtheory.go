@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	bigZero = big.NewInt(0)
+	bigOne  = big.NewInt(1)
+	bigTwo  = big.NewInt(2)
+)
+
+// CRT solves the system x = residues[i] mod moduli[i] with the Chinese
+// Remainder Theorem. It returns the least non-negative solution x together
+// with the product of the moduli, since x is only unique modulo that product.
+// The moduli must be pairwise coprime.
+func CRT(residues, moduli []*big.Int) (*big.Int, *big.Int, error) {
+	if len(residues) != len(moduli) {
+		return nil, nil, fmt.Errorf("CRT needs as many residues as moduli, got %d and %d", len(residues), len(moduli))
+	}
+	if len(moduli) == 0 {
+		return nil, nil, errors.New("CRT needs at least one modulus")
+	}
+	for i := range moduli {
+		if moduli[i].Sign() <= 0 {
+			return nil, nil, fmt.Errorf("modulus %d is not positive: %v", i, moduli[i])
+		}
+		for j := i + 1; j < len(moduli); j++ {
+			gcd := new(big.Int).GCD(nil, nil, moduli[i], moduli[j])
+			if gcd.Cmp(bigOne) != 0 {
+				return nil, nil, fmt.Errorf("moduli %d and %d share the factor %v", i, j, gcd)
+			}
+		}
+	}
+
+	product := big.NewInt(1)
+	for _, m := range moduli {
+		product.Mul(product, m)
+	}
+
+	x := new(big.Int)
+	for i, m := range moduli {
+		// ms is the product of all moduli but the i-th one
+		ms := new(big.Int).Div(product, m)
+		inverse := new(big.Int).ModInverse(ms, m)
+		term := new(big.Int).Mul(residues[i], ms)
+		term.Mul(term, inverse)
+		x.Add(x, term)
+	}
+	x.Mod(x, product)
+	return x, product, nil
+}
+
+// IntegerRoot returns the integer n-th root of x, i.e. the largest r with
+// r**n <= x, and whether the root is exact. It's Newton's method starting
+// from a power of two that is guaranteed to be above the root.
+func IntegerRoot(x *big.Int, n int) (*big.Int, bool) {
+	if x.Sign() < 0 || n < 1 {
+		panic(fmt.Sprintf("Error: integer %d-th root of %v", n, x))
+	}
+	if x.Sign() == 0 || n == 1 {
+		return new(big.Int).Set(x), true
+	}
+
+	bigN := big.NewInt(int64(n))
+	bigNMinusOne := big.NewInt(int64(n - 1))
+	r := new(big.Int).Lsh(bigOne, uint(x.BitLen()/n+1))
+	for {
+		// next = ((n-1)*r + x/r**(n-1)) / n
+		next := new(big.Int).Exp(r, bigNMinusOne, nil)
+		next.Div(x, next)
+		next.Add(next, new(big.Int).Mul(bigNMinusOne, r))
+		next.Div(next, bigN)
+		if next.Cmp(r) >= 0 {
+			break
+		}
+		r = next
+	}
+	exact := new(big.Int).Exp(r, bigN, nil).Cmp(x) == 0
+	return r, exact
+}
@@ -0,0 +1,51 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bigInts(xs ...int64) []*big.Int {
+	out := make([]*big.Int, len(xs))
+	for i, x := range xs {
+		out[i] = big.NewInt(x)
+	}
+	return out
+}
+
+func TestCRT(t *testing.T) {
+	x, product, err := CRT(bigInts(2, 3, 2), bigInts(3, 5, 7))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(23), x.Int64())
+	assert.Equal(t, int64(105), product.Int64())
+
+	// moduli that aren't pairwise coprime are rejected instead of panicking
+	_, _, err = CRT(bigInts(1, 2), bigInts(4, 6))
+	assert.Error(t, err)
+	_, _, err = CRT(bigInts(1, 2), bigInts(5))
+	assert.Error(t, err)
+	_, _, err = CRT(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestIntegerRoot(t *testing.T) {
+	root, exact := IntegerRoot(big.NewInt(27), 3)
+	assert.True(t, exact)
+	assert.Equal(t, int64(3), root.Int64())
+
+	root, exact = IntegerRoot(big.NewInt(28), 3)
+	assert.False(t, exact)
+	assert.Equal(t, int64(3), root.Int64())
+
+	root, exact = IntegerRoot(big.NewInt(26), 3)
+	assert.False(t, exact)
+	assert.Equal(t, int64(2), root.Int64())
+
+	x, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	x5 := new(big.Int).Exp(x, big.NewInt(5), nil)
+	root, exact = IntegerRoot(x5, 5)
+	assert.True(t, exact)
+	assert.Equal(t, x, root)
+}
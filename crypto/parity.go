@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"math/big"
+)
+
+// ParityOracle tells whether an RSA ciphertext decrypts to an even plaintext.
+type ParityOracle func(c *big.Int) bool
+
+// ParityProgress is called after every step of ParityOracleAttack with the
+// step number and the current upper bound of the plaintext. Printing
+// upper.Bytes() after each call shows the plaintext come into focus.
+type ParityProgress func(step int, upper *big.Int)
+
+// NewParityOracle makes an oracle that leaks the lowest bit of plaintexts
+// decrypted with priv.
+func NewParityOracle(priv *RSAPrivateKey) ParityOracle {
+	return func(c *big.Int) bool {
+		return priv.Decrypt(c).Bit(0) == 0
+	}
+}
+
+// ParityOracleAttack decrypts c with a parity oracle. Multiplying the
+// ciphertext by 2**e doubles the plaintext; 2m mod n is even exactly when 2m
+// didn't wrap around the odd modulus, i.e. when m < n/2. Each query halves the
+// interval containing m, so it takes log2(n) queries.
+// The bounds are kept exact as m in [k*n/2**i, (k+1)*n/2**i), which avoids
+// the rounding trouble of halving integer bounds. progress may be nil.
+// Link: https://cryptopals.com/sets/6/challenges/46
+func ParityOracleAttack(pub *RSAPublicKey, c *big.Int, isEven ParityOracle, progress ParityProgress) *big.Int {
+	double := pub.Encrypt(bigTwo)
+	c = new(big.Int).Set(c)
+	k := new(big.Int)
+	upper := new(big.Int)
+	steps := pub.N.BitLen()
+	for i := 1; i <= steps; i++ {
+		c.Mul(c, double)
+		c.Mod(c, pub.N)
+		k.Lsh(k, 1)
+		if !isEven(c) {
+			k.Add(k, bigOne)
+		}
+		if progress != nil {
+			// floor((k+1)*n / 2**i)
+			upper.Add(k, bigOne)
+			upper.Mul(upper, pub.N)
+			upper.Rsh(upper, uint(i))
+			progress(i, new(big.Int).Set(upper))
+		}
+	}
+
+	// the interval is now narrower than 1, m = ceil(k*n / 2**steps)
+	m := new(big.Int).Mul(k, pub.N)
+	m.Add(m, new(big.Int).Sub(new(big.Int).Lsh(bigOne, uint(steps)), bigOne))
+	return m.Rsh(m, uint(steps))
+}
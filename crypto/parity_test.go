@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParityOracleAttack(t *testing.T) {
+	priv, err := GenerateRSAKey(1024, 65537)
+	assert.NoError(t, err)
+	plaintext, err := base64.StdEncoding.DecodeString("VGhhdCdzIHdoeSBJIGZvdW5kIHlvdSBkb24ndCBwbGF5IGFyb3VuZCB3aXRoIHRoZSBGdW5reSBDb2xkIE1lZGluYQ==")
+	assert.NoError(t, err)
+	m := new(big.Int).SetBytes(plaintext)
+	c := priv.Encrypt(m)
+
+	oracle := NewParityOracle(priv)
+	assert.True(t, oracle(priv.Encrypt(big.NewInt(42))))
+	assert.False(t, oracle(priv.Encrypt(big.NewInt(43))))
+
+	var steps []int
+	previous := new(big.Int).Set(priv.N)
+	progress := func(step int, upper *big.Int) {
+		steps = append(steps, step)
+		// the upper bound only ever comes down, and never below the plaintext
+		assert.True(t, upper.Cmp(previous) <= 0)
+		assert.True(t, upper.Cmp(m) >= 0)
+		previous = upper
+	}
+	got := ParityOracleAttack(&priv.RSAPublicKey, c, oracle, progress)
+	assert.Equal(t, string(plaintext), string(got.Bytes()))
+	assert.Len(t, steps, priv.N.BitLen())
+	assert.Equal(t, m, previous)
+
+	// the progress hook is optional
+	got = ParityOracleAttack(&priv.RSAPublicKey, c, oracle, nil)
+	assert.Equal(t, m, got)
+}
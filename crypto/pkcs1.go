@@ -0,0 +1,191 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// HashAlgorithm names a hash function that can go into a PKCS#1 v1.5 signature.
+type HashAlgorithm int
+
+// Hash functions supported by the PKCS#1 v1.5 signature code.
+const (
+	SHA1 HashAlgorithm = iota
+	SHA256
+)
+
+// digestInfoPrefixes are the DER encodings of the ASN.1 DigestInfo structure
+// up to the digest itself, as listed in RFC 8017 section 9.2.
+var digestInfoPrefixes = map[HashAlgorithm][]byte{
+	SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+}
+
+// Digest hashes msg with the algorithm.
+func (h HashAlgorithm) Digest(msg []byte) []byte {
+	switch h {
+	case SHA1:
+		digest := sha1.Sum(msg)
+		return digest[:]
+	case SHA256:
+		digest := sha256.Sum256(msg)
+		return digest[:]
+	}
+	panic(fmt.Sprintf("Error: unknown hash algorithm %d", h))
+}
+
+// digestInfo returns the DER encoded DigestInfo of msg.
+func (h HashAlgorithm) digestInfo(msg []byte) []byte {
+	prefix, ok := digestInfoPrefixes[h]
+	if !ok {
+		panic(fmt.Sprintf("Error: unknown hash algorithm %d", h))
+	}
+	return append(append([]byte{}, prefix...), h.Digest(msg)...)
+}
+
+// leftPad returns the big-endian bytes of x left-padded with zeros to size bytes.
+func leftPad(x *big.Int, size int) []byte {
+	b := x.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
+
+// EncodePKCS1v15Signature builds the k-byte block 00 01 FF..FF 00 DigestInfo
+// that gets signed for msg.
+func EncodePKCS1v15Signature(h HashAlgorithm, msg []byte, k int) ([]byte, error) {
+	info := h.digestInfo(msg)
+	// at least 8 bytes of FF padding
+	if k < len(info)+11 {
+		return nil, fmt.Errorf("%d byte modulus is too short for a %d byte DigestInfo", k, len(info))
+	}
+	block := make([]byte, k)
+	block[1] = 0x01
+	for i := 2; i < k-len(info)-1; i++ {
+		block[i] = 0xff
+	}
+	copy(block[k-len(info):], info)
+	return block, nil
+}
+
+// SignPKCS1v15 signs msg with RSA and PKCS#1 v1.5 padding.
+func SignPKCS1v15(priv *RSAPrivateKey, h HashAlgorithm, msg []byte) ([]byte, error) {
+	block, err := EncodePKCS1v15Signature(h, msg, priv.Size())
+	if err != nil {
+		return nil, err
+	}
+	s := priv.Decrypt(new(big.Int).SetBytes(block))
+	return leftPad(s, priv.Size()), nil
+}
+
+// VerifyPKCS1v15 checks a PKCS#1 v1.5 signature the right way: it re-encodes
+// the expected block and compares the whole thing.
+func VerifyPKCS1v15(pub *RSAPublicKey, h HashAlgorithm, msg, sig []byte) bool {
+	if len(sig) != pub.Size() {
+		return false
+	}
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(pub.N) >= 0 {
+		return false
+	}
+	want, err := EncodePKCS1v15Signature(h, msg, pub.Size())
+	if err != nil {
+		return false
+	}
+	got := leftPad(pub.Encrypt(s), pub.Size())
+	return bytes.Equal(want, got)
+}
+
+// SloppyVerifyPKCS1v15 checks a PKCS#1 v1.5 signature by parsing the block
+// from the left: 00 01, a run of FF, 00, DigestInfo. It never checks that the
+// DigestInfo sits at the end of the block, so anything may trail it.
+func SloppyVerifyPKCS1v15(pub *RSAPublicKey, h HashAlgorithm, msg, sig []byte) bool {
+	block := leftPad(pub.Encrypt(new(big.Int).SetBytes(sig)), pub.Size())
+	if len(block) < 3 || block[0] != 0x00 || block[1] != 0x01 || block[2] != 0xff {
+		return false
+	}
+	i := 2
+	for i < len(block) && block[i] == 0xff {
+		i++
+	}
+	if i == len(block) || block[i] != 0x00 {
+		return false
+	}
+	return bytes.HasPrefix(block[i+1:], h.digestInfo(msg))
+}
+
+// ForgePKCS1v15Signature forges a signature on msg that SloppyVerifyPKCS1v15
+// accepts, without the private key. It works for small public exponents:
+// the block 00 01 FF 00 DigestInfo 00..00 is rounded up to the next perfect
+// e-th power, which only disturbs the trailing garbage as long as the modulus
+// is long enough.
+// Link: https://cryptopals.com/sets/6/challenges/42
+func ForgePKCS1v15Signature(pub *RSAPublicKey, h HashAlgorithm, msg []byte) ([]byte, error) {
+	if !pub.E.IsInt64() || pub.E.Int64() > 1<<16 {
+		return nil, errors.New("signature forgery needs a small public exponent")
+	}
+	k := pub.Size()
+	info := h.digestInfo(msg)
+	if k < len(info)+4 {
+		return nil, fmt.Errorf("%d byte modulus is too short for a %d byte DigestInfo", k, len(info))
+	}
+	block := make([]byte, k)
+	block[1] = 0x01
+	block[2] = 0xff
+	copy(block[4:], info)
+
+	s, exact := IntegerRoot(new(big.Int).SetBytes(block), int(pub.E.Int64()))
+	if !exact {
+		s.Add(s, bigOne)
+	}
+	sig := leftPad(s, k)
+	if !SloppyVerifyPKCS1v15(pub, h, msg, sig) {
+		return nil, fmt.Errorf("%d bit modulus leaves too little room for garbage", pub.N.BitLen())
+	}
+	return sig, nil
+}
+
+// PadPKCS1v15Encryption pads msg into the k-byte block 00 02 PS 00 msg,
+// where PS is at least 8 random non-zero bytes.
+func PadPKCS1v15Encryption(msg []byte, k int) ([]byte, error) {
+	if len(msg) > k-11 {
+		return nil, fmt.Errorf("message of %d bytes is too long for a %d byte modulus", len(msg), k)
+	}
+	block := make([]byte, k)
+	block[1] = 0x02
+	ps := block[2 : k-len(msg)-1]
+	if _, err := rand.Read(ps); err != nil {
+		return nil, err
+	}
+	for i := range ps {
+		for ps[i] == 0 {
+			if _, err := rand.Read(ps[i : i+1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	copy(block[k-len(msg):], msg)
+	return block, nil
+}
+
+// UnpadPKCS1v15Encryption strips PKCS#1 v1.5 encryption padding.
+func UnpadPKCS1v15Encryption(block []byte) ([]byte, error) {
+	if len(block) < 11 || block[0] != 0x00 || block[1] != 0x02 {
+		return nil, errors.New("block doesn't start with 00 02")
+	}
+	for i := 2; i < len(block); i++ {
+		if block[i] == 0x00 {
+			if i < 10 {
+				return nil, errors.New("padding string is shorter than 8 bytes")
+			}
+			return block[i+1:], nil
+		}
+	}
+	return nil, errors.New("padding string isn't terminated")
+}
@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignPKCS1v15(t *testing.T) {
+	priv, err := GenerateRSAKey(1024, 3)
+	assert.NoError(t, err)
+	msg := []byte("hi mom")
+
+	for _, h := range []HashAlgorithm{SHA1, SHA256} {
+		sig, err := SignPKCS1v15(priv, h, msg)
+		assert.NoError(t, err)
+		assert.True(t, VerifyPKCS1v15(&priv.RSAPublicKey, h, msg, sig))
+		assert.True(t, SloppyVerifyPKCS1v15(&priv.RSAPublicKey, h, msg, sig))
+		assert.False(t, VerifyPKCS1v15(&priv.RSAPublicKey, h, []byte("hi dad"), sig))
+		assert.False(t, SloppyVerifyPKCS1v15(&priv.RSAPublicKey, h, []byte("hi dad"), sig))
+	}
+
+	// our signatures are the same as the standard library's
+	stdPub := &rsa.PublicKey{N: priv.N, E: int(priv.E.Int64())}
+	sig, err := SignPKCS1v15(priv, SHA256, msg)
+	assert.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPKCS1v15(stdPub, crypto.SHA256, SHA256.Digest(msg), sig))
+	sig, err = SignPKCS1v15(priv, SHA1, msg)
+	assert.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPKCS1v15(stdPub, crypto.SHA1, SHA1.Digest(msg), sig))
+}
+
+func TestForgePKCS1v15Signature(t *testing.T) {
+	msg := []byte("hi mom")
+	tests := []struct {
+		bits int
+		h    HashAlgorithm
+	}{
+		{1024, SHA1},
+		{2048, SHA256},
+	}
+	for _, test := range tests {
+		priv, err := GenerateRSAKey(test.bits, 3)
+		assert.NoError(t, err)
+		pub := &priv.RSAPublicKey
+
+		forged, err := ForgePKCS1v15Signature(pub, test.h, msg)
+		assert.NoError(t, err)
+		assert.True(t, SloppyVerifyPKCS1v15(pub, test.h, msg, forged))
+		assert.False(t, VerifyPKCS1v15(pub, test.h, msg, forged))
+	}
+
+	// a 1024 bit modulus is too short to hide a SHA-256 forgery
+	priv, err := GenerateRSAKey(1024, 3)
+	assert.NoError(t, err)
+	_, err = ForgePKCS1v15Signature(&priv.RSAPublicKey, SHA256, msg)
+	assert.Error(t, err)
+}
+
+func TestPadPKCS1v15Encryption(t *testing.T) {
+	msg := []byte("kick it, CC")
+	block, err := PadPKCS1v15Encryption(msg, 32)
+	assert.NoError(t, err)
+	assert.Len(t, block, 32)
+	assert.Equal(t, []byte{0x00, 0x02}, block[:2])
+	assert.NotContains(t, block[2:20], byte(0x00))
+	got, err := UnpadPKCS1v15Encryption(block)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, got)
+
+	_, err = PadPKCS1v15Encryption(msg, 21)
+	assert.Error(t, err)
+
+	bad := append([]byte{}, block...)
+	bad[1] = 0x01
+	_, err = UnpadPKCS1v15Encryption(bad)
+	assert.Error(t, err)
+	bad = append([]byte{}, block...)
+	bad[5] = 0x00
+	_, err = UnpadPKCS1v15Encryption(bad)
+	assert.Error(t, err)
+}
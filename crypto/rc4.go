@@ -0,0 +1,224 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// RC4 is the RC4 stream cipher.
+type RC4 struct {
+	s    [256]byte
+	i, j uint8
+}
+
+// NewRC4 runs the key schedule of RC4. The key is 1 to 256 bytes.
+func NewRC4(key []byte) (*RC4, error) {
+	if len(key) < 1 || len(key) > 256 {
+		return nil, fmt.Errorf("invalid RC4 key size %d", len(key))
+	}
+	c := &RC4{}
+	for i := range c.s {
+		c.s[i] = byte(i)
+	}
+	var j uint8
+	for i := 0; i < 256; i++ {
+		j += c.s[i] + key[i%len(key)]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+	}
+	return c, nil
+}
+
+// XORKeyStream XORs src with the next bytes of the keystream into dst, which
+// may be src itself.
+func (c *RC4) XORKeyStream(dst, src []byte) {
+	i, j := c.i, c.j
+	for k, b := range src {
+		i++
+		j += c.s[i]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+		dst[k] = b ^ c.s[c.s[i]+c.s[j]]
+	}
+	c.i, c.j = i, j
+}
+
+// RC4Oracle returns the encryption of the attacker's request followed by a
+// secret.
+type RC4Oracle func(request []byte) ([]byte, error)
+
+// NewRC4CookieOracle makes an oracle that encrypts request || cookie with
+// RC4 under a fresh random 128-bit key every time. It's safe to call from
+// several goroutines.
+func NewRC4CookieOracle(cookie []byte) RC4Oracle {
+	return func(request []byte) ([]byte, error) {
+		key := make([]byte, 16)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		c, err := NewRC4(key)
+		if err != nil {
+			return nil, err
+		}
+		ciphertext := append(append([]byte{}, request...), cookie...)
+		c.XORKeyStream(ciphertext, ciphertext)
+		return ciphertext, nil
+	}
+}
+
+// RC4Progress is told how many of the total encryptions are done.
+type RC4Progress func(done, total int64)
+
+// Biased keystream bytes of RC4: Z16 is 240 and Z32 is 224 about 2**-5 more
+// often than the other values, over random keys.
+const (
+	rc4Z16, rc4Z16Value = 15, 240
+	rc4Z32, rc4Z32Value = 31, 224
+	// how often workers report to the progress hook
+	rc4ProgressStep = 1 << 14
+)
+
+// rc4Counts holds the ciphertext byte frequencies at Z16 and Z32 for every
+// offset of the request in front of the secret.
+type rc4Counts [32][2][256]int64
+
+// RC4BiasAttack recovers the secret of an RC4Oracle, up to 32 bytes, from the
+// biases of the 16th and 32nd keystream bytes. The request is padded so each
+// secret byte falls at those positions in turn, then the most frequent
+// ciphertext byte there is the secret byte XOR the biased value. It makes
+// trials encryptions for each padding length, split over the workers, which
+// keep their own frequency tables that are merged at the end. progress may
+// be nil; it's only called from the calling goroutine.
+// Link: https://cryptopals.com/sets/7/challenges/56
+func RC4BiasAttack(oracle RC4Oracle, trials int64, workers int, progress RC4Progress) ([]byte, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	ciphertext, err := oracle(nil)
+	if err != nil {
+		return nil, err
+	}
+	n := len(ciphertext)
+	if n > rc4Z32+1 {
+		return nil, fmt.Errorf("secret of %d bytes, the biases only reach %d", n, rc4Z32+1)
+	}
+
+	// padding lengths that put a secret byte on Z16 or Z32
+	var pads []int
+	for pad := 0; pad <= rc4Z32; pad++ {
+		if (pad <= rc4Z16 && rc4Z16-pad < n) || rc4Z32-pad < n {
+			pads = append(pads, pad)
+		}
+	}
+	total := trials * int64(len(pads))
+
+	tables := make([]*rc4Counts, workers)
+	reports := make(chan int64, workers)
+	errs := make(chan error, workers)
+	// closed on the first error, so the other workers stop early
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		// the first workers take the remainder of the trials
+		share := trials / int64(workers)
+		if int64(w) < trials%int64(workers) {
+			share++
+		}
+		tables[w] = &rc4Counts{}
+		wg.Add(1)
+		go func(counts *rc4Counts, share int64) {
+			defer wg.Done()
+			request := make([]byte, rc4Z32)
+			for i := range request {
+				request[i] = 'A'
+			}
+			var done int64
+			for _, pad := range pads {
+				for t := int64(0); t < share; t++ {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					c, err := oracle(request[:pad])
+					if err != nil {
+						errs <- err
+						stopOnce.Do(func() { close(stop) })
+						return
+					}
+					counts[pad][0][c[rc4Z16]]++
+					if len(c) > rc4Z32 {
+						counts[pad][1][c[rc4Z32]]++
+					}
+					if done++; done == rc4ProgressStep {
+						reports <- done
+						done = 0
+					}
+				}
+			}
+			if done > 0 {
+				reports <- done
+			}
+		}(tables[w], share)
+	}
+	go func() {
+		wg.Wait()
+		close(reports)
+	}()
+
+	var done int64
+	for r := range reports {
+		done += r
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+	close(errs)
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+
+	merged := &rc4Counts{}
+	for _, counts := range tables {
+		for pad := range counts {
+			for z := range counts[pad] {
+				for b, count := range counts[pad][z] {
+					merged[pad][z][b] += count
+				}
+			}
+		}
+	}
+	return rc4Guess(merged, n)
+}
+
+// rc4Guess picks every secret byte by adding up the votes of Z16 and Z32,
+// the two biases being about as strong.
+func rc4Guess(counts *rc4Counts, n int) ([]byte, error) {
+	secret := make([]byte, n)
+	for p := range secret {
+		var votes [256]int64
+		found := false
+		if pad := rc4Z16 - p; pad >= 0 {
+			for c, count := range counts[pad][0] {
+				votes[byte(c)^rc4Z16Value] += count
+				found = found || count > 0
+			}
+		}
+		if pad := rc4Z32 - p; pad >= 0 {
+			for c, count := range counts[pad][1] {
+				votes[byte(c)^rc4Z32Value] += count
+				found = found || count > 0
+			}
+		}
+		if !found {
+			return nil, errors.New("no encryptions to count")
+		}
+		for b := range votes {
+			if votes[b] > votes[secret[p]] {
+				secret[p] = byte(b)
+			}
+		}
+	}
+	return secret, nil
+}
@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"crypto/rand"
+	stdrc4 "crypto/rc4"
+	"errors"
+	mathrand "math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRC4(t *testing.T) {
+	for _, size := range []int{1, 5, 16, 256} {
+		key := make([]byte, size)
+		_, err := rand.Read(key)
+		assert.NoError(t, err)
+		msg := make([]byte, 1000)
+		_, err = rand.Read(msg)
+		assert.NoError(t, err)
+
+		c, err := NewRC4(key)
+		assert.NoError(t, err)
+		got := make([]byte, len(msg))
+		// in two pieces to check the state carries over
+		c.XORKeyStream(got[:100], msg[:100])
+		c.XORKeyStream(got[100:], msg[100:])
+
+		std, err := stdrc4.NewCipher(key)
+		assert.NoError(t, err)
+		want := make([]byte, len(msg))
+		std.XORKeyStream(want, msg)
+		assert.Equal(t, want, got)
+	}
+	_, err := NewRC4(nil)
+	assert.Error(t, err)
+}
+
+func TestRC4BiasAttack(t *testing.T) {
+	// a stand-in oracle with much stronger biases than RC4, so the counting
+	// can be checked quickly
+	cookie := []byte("BE SURE TO DRINK YOUR OVALTINE")
+	var mu sync.Mutex
+	random := mathrand.New(mathrand.NewSource(1))
+	oracle := func(request []byte) ([]byte, error) {
+		c := append(append([]byte{}, request...), cookie...)
+		mu.Lock()
+		defer mu.Unlock()
+		for i := range c {
+			switch {
+			case i == rc4Z16 && random.Intn(4) == 0:
+				c[i] ^= rc4Z16Value
+			case i == rc4Z32 && random.Intn(4) == 0:
+				c[i] ^= rc4Z32Value
+			default:
+				c[i] ^= byte(random.Intn(256))
+			}
+		}
+		return c, nil
+	}
+	var last, total int64
+	progress := func(done, all int64) {
+		assert.True(t, done > last)
+		last, total = done, all
+	}
+	secret, err := RC4BiasAttack(oracle, 5000, 3, progress)
+	assert.NoError(t, err)
+	assert.Equal(t, cookie, secret)
+	assert.Equal(t, total, last)
+	assert.Equal(t, int64(5000*32), total)
+
+	_, err = RC4BiasAttack(NewRC4CookieOracle(make([]byte, 33)), 1, 1, nil)
+	assert.Error(t, err)
+}
+
+func TestRC4BiasAttackProgress(t *testing.T) {
+	// one byte of secret takes two padding lengths, so each of the 4 workers
+	// makes exactly rc4ProgressStep calls and reports once
+	oracle := func(request []byte) ([]byte, error) {
+		return make([]byte, len(request)+1), nil
+	}
+	var calls int
+	var last int64
+	progress := func(done, all int64) {
+		calls++
+		assert.True(t, done > last)
+		last = done
+	}
+	_, err := RC4BiasAttack(oracle, 2*rc4ProgressStep, 4, progress)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, int64(4*rc4ProgressStep), last)
+}
+
+func TestRC4BiasAttackError(t *testing.T) {
+	// the workers stop at the first error rather than finishing their share
+	var calls int64
+	failing := errors.New("oracle down")
+	oracle := func(request []byte) ([]byte, error) {
+		if atomic.AddInt64(&calls, 1) > 10 {
+			return nil, failing
+		}
+		return make([]byte, len(request)+4), nil
+	}
+	_, err := RC4BiasAttack(oracle, 1<<20, 4, nil)
+	assert.Equal(t, failing, err)
+	assert.True(t, atomic.LoadInt64(&calls) <= 10+4)
+}
+
+func TestRC4BiasAttackRC4(t *testing.T) {
+	if testing.Short() {
+		t.Skip("needs millions of RC4 encryptions")
+	}
+	cookie := []byte("Q")
+	secret, err := RC4BiasAttack(NewRC4CookieOracle(cookie), 1<<22, 2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, cookie, secret)
+}
@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// RSAPublicKey is a textbook RSA public key, no padding involved.
+type RSAPublicKey struct {
+	N *big.Int
+	E *big.Int
+}
+
+// RSAPrivateKey is a textbook RSA private key.
+type RSAPrivateKey struct {
+	RSAPublicKey
+	D *big.Int
+}
+
+// GenerateRSAKey makes a key with a modulus of `bits` bits and public exponent e.
+// Primes are drawn until e is invertible mod (p-1)(q-1), which matters for
+// small exponents like e=3.
+func GenerateRSAKey(bits, e int) (*RSAPrivateKey, error) {
+	if bits < 16 {
+		return nil, fmt.Errorf("RSA modulus of %d bits is too small", bits)
+	}
+	bigE := big.NewInt(int64(e))
+	for {
+		p, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(rand.Reader, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits {
+			continue
+		}
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, bigOne), new(big.Int).Sub(q, bigOne))
+		d := new(big.Int).ModInverse(bigE, phi)
+		if d == nil {
+			// e and phi aren't coprime, try other primes
+			continue
+		}
+		return &RSAPrivateKey{RSAPublicKey{n, bigE}, d}, nil
+	}
+}
+
+// Size returns the length of the modulus in bytes.
+func (pub *RSAPublicKey) Size() int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+// Encrypt computes m**e mod n.
+func (pub *RSAPublicKey) Encrypt(m *big.Int) *big.Int {
+	return new(big.Int).Exp(m, pub.E, pub.N)
+}
+
+// Decrypt computes c**d mod n.
+func (priv *RSAPrivateKey) Decrypt(c *big.Int) *big.Int {
+	return new(big.Int).Exp(c, priv.D, priv.N)
+}
+
+// HastadBroadcastAttack recovers a message that was encrypted with the same
+// small public exponent e under at least e different moduli.
+// CRT gives m**e modulo the product of the moduli, and since m is smaller
+// than every modulus, m**e is smaller than that product, so m is simply the
+// integer e-th root.
+// Link: https://cryptopals.com/sets/5/challenges/40
+func HastadBroadcastAttack(e int, ciphertexts, moduli []*big.Int) (*big.Int, error) {
+	if e < 2 {
+		return nil, fmt.Errorf("broadcast attack with exponent %d", e)
+	}
+	if len(ciphertexts) < e {
+		return nil, fmt.Errorf("broadcast attack with e=%d needs at least %d ciphertexts, got %d", e, e, len(ciphertexts))
+	}
+	me, _, err := CRT(ciphertexts, moduli)
+	if err != nil {
+		return nil, err
+	}
+	m, exact := IntegerRoot(me, e)
+	if !exact {
+		return nil, errors.New("CRT result is not a perfect power, the ciphertexts don't share a message")
+	}
+	return m, nil
+}
@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSAEncryptDecrypt(t *testing.T) {
+	priv, err := GenerateRSAKey(512, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 512, priv.N.BitLen())
+	m := new(big.Int).SetBytes([]byte("Cooking MC's like a pound of bacon"))
+	c := priv.Encrypt(m)
+	assert.NotEqual(t, m, c)
+	assert.Equal(t, m, priv.Decrypt(c))
+}
+
+func TestHastadBroadcastAttack(t *testing.T) {
+	plaintext := "Burning 'em, if you ain't quick and nimble"
+	m := new(big.Int).SetBytes([]byte(plaintext))
+	for _, e := range []int{3, 5} {
+		var ciphertexts, moduli []*big.Int
+		for i := 0; i < e; i++ {
+			priv, err := GenerateRSAKey(512, e)
+			assert.NoError(t, err)
+			ciphertexts = append(ciphertexts, priv.Encrypt(m))
+			moduli = append(moduli, priv.N)
+		}
+		got, err := HastadBroadcastAttack(e, ciphertexts, moduli)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, string(got.Bytes()))
+
+		// not enough ciphertexts
+		_, err = HastadBroadcastAttack(e, ciphertexts[1:], moduli[1:])
+		assert.Error(t, err)
+	}
+
+	// the same modulus twice isn't coprime
+	priv, err := GenerateRSAKey(512, 3)
+	assert.NoError(t, err)
+	c := priv.Encrypt(m)
+	_, err = HastadBroadcastAttack(3, []*big.Int{c, c, c}, []*big.Int{priv.N, priv.N, priv.N})
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// collideStates finds blocks b1 and b2 with Compress(s1, b1) = Compress(s2, b2)
+// by growing a birthday table on each side.
+func (h *ToyHash) collideStates(s1, s2 []byte) ([]byte, []byte, []byte, error) {
+	seen1 := make(map[string][]byte)
+	seen2 := make(map[string][]byte)
+	for {
+		b1 := make([]byte, ToyHashBlockSize)
+		b2 := make([]byte, ToyHashBlockSize)
+		if _, err := rand.Read(b1); err != nil {
+			return nil, nil, nil, err
+		}
+		if _, err := rand.Read(b2); err != nil {
+			return nil, nil, nil, err
+		}
+		next1 := h.Compress(s1, b1)
+		if other, ok := seen2[string(next1)]; ok {
+			return b1, other, next1, nil
+		}
+		seen1[string(next1)] = b1
+		next2 := h.Compress(s2, b2)
+		if other, ok := seen1[string(next2)]; ok {
+			return other, b2, next2, nil
+		}
+		seen2[string(next2)] = b2
+	}
+}
+
+// ExpandableMessage is a set of messages of every length from K to
+// K + 2**K - 1 blocks that all reach the same Final state.
+// Step i offers either a single block or 2**(K-1-i) dummy blocks followed by
+// one block, both leading to the same state.
+type ExpandableMessage struct {
+	K           int
+	Short, Long [][]byte
+	Final       []byte
+}
+
+// ExpandableMessage builds an expandable message starting at state with k
+// collisions. Each step costs 2**(K-1-i) compressions for the dummy blocks
+// plus one collision.
+// Link: https://cryptopals.com/sets/7/challenges/53
+func (h *ToyHash) ExpandableMessage(state []byte, k int) (*ExpandableMessage, error) {
+	e := &ExpandableMessage{K: k}
+	for i := 0; i < k; i++ {
+		dummy := make([]byte, ToyHashBlockSize*(1<<uint(k-1-i)))
+		dummyState := h.Chain(state, dummy)
+		short, last, next, err := h.collideStates(state, dummyState)
+		if err != nil {
+			return nil, err
+		}
+		e.Short = append(e.Short, short)
+		e.Long = append(e.Long, append(dummy, last...))
+		state = next
+	}
+	e.Final = state
+	return e, nil
+}
+
+// Produce returns the message of the given number of blocks.
+func (e *ExpandableMessage) Produce(blocks int) ([]byte, error) {
+	extra := blocks - e.K
+	if extra < 0 || extra >= 1<<uint(e.K) {
+		return nil, fmt.Errorf("expandable message of %d blocks, can do %d to %d", blocks, e.K, e.K+1<<uint(e.K)-1)
+	}
+	var msg []byte
+	for i := 0; i < e.K; i++ {
+		// the long message of step i adds 2**(K-1-i) blocks
+		if extra>>uint(e.K-1-i)&1 == 1 {
+			msg = append(msg, e.Long[i]...)
+		} else {
+			msg = append(msg, e.Short[i]...)
+		}
+	}
+	return msg, nil
+}
+
+// SecondPreimage is the result of SecondPreimageAttack.
+type SecondPreimage struct {
+	Forged []byte
+	// OriginalHash and ForgedHash are the full hashes of both messages,
+	// they're equal when the attack worked.
+	OriginalHash, ForgedHash []byte
+	// Bridge is the number of blocks of the original message the forgery
+	// replaced.
+	Bridge int
+}
+
+// SecondPreimageAttack finds a second message with the same hash as a long
+// message of about 2**k blocks, after Kelsey and Schneier. The attack looks
+// for a bridge block from the end of an expandable message to any
+// intermediate state of the original, then expands the message to the right
+// length so the MD strengthening matches as well. The bridge takes about
+// 2**(b-k) compressions for a hash of b bits instead of 2**b.
+// Link: https://cryptopals.com/sets/7/challenges/53
+func (h *ToyHash) SecondPreimageAttack(msg []byte, k int) (*SecondPreimage, error) {
+	n := len(msg) / ToyHashBlockSize
+	if n < k+2 {
+		return nil, fmt.Errorf("message of %d blocks is too short for k=%d", n, k)
+	}
+
+	// states reached after j blocks, for the j the expandable message can reach
+	targets := make(map[string]int)
+	state := h.IV
+	for j := 1; j <= n; j++ {
+		state = h.Compress(state, msg[(j-1)*ToyHashBlockSize:j*ToyHashBlockSize])
+		if j-1 >= k && j-1 < k+1<<uint(k) {
+			targets[string(state)] = j
+		}
+	}
+
+	e, err := h.ExpandableMessage(h.IV, k)
+	if err != nil {
+		return nil, err
+	}
+	bridge := make([]byte, ToyHashBlockSize)
+	for tries := 0; tries < 1<<uint(8*h.Size+4); tries++ {
+		if _, err := rand.Read(bridge); err != nil {
+			return nil, err
+		}
+		j, ok := targets[string(h.Compress(e.Final, bridge))]
+		if !ok {
+			continue
+		}
+		prefix, err := e.Produce(j - 1)
+		if err != nil {
+			return nil, err
+		}
+		forged := append(append(prefix, bridge...), msg[j*ToyHashBlockSize:]...)
+		return &SecondPreimage{forged, h.Sum(msg), h.Sum(forged), j}, nil
+	}
+	return nil, errors.New("no bridge block found")
+}
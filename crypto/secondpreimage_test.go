@@ -0,0 +1,48 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandableMessage(t *testing.T) {
+	h := NewToyHash(2)
+	k := 4
+	e, err := h.ExpandableMessage(h.IV, k)
+	assert.NoError(t, err)
+	for blocks := k; blocks < k+1<<uint(k); blocks++ {
+		msg, err := e.Produce(blocks)
+		assert.NoError(t, err)
+		assert.Len(t, msg, blocks*ToyHashBlockSize)
+		assert.Equal(t, e.Final, h.Chain(h.IV, msg))
+	}
+	_, err = e.Produce(k - 1)
+	assert.Error(t, err)
+	_, err = e.Produce(k + 1<<uint(k))
+	assert.Error(t, err)
+}
+
+func TestSecondPreimageAttack(t *testing.T) {
+	for _, size := range []int{2, 3} {
+		h := NewToyHash(size)
+		k := 10
+		// a message of 2**k blocks with a ragged end
+		msg := make([]byte, ToyHashBlockSize<<uint(k)+5)
+		_, err := rand.Read(msg)
+		assert.NoError(t, err)
+
+		result, err := h.SecondPreimageAttack(msg, k)
+		assert.NoError(t, err)
+		assert.False(t, bytes.Equal(msg, result.Forged))
+		assert.Len(t, result.Forged, len(msg))
+		assert.Equal(t, result.OriginalHash, result.ForgedHash)
+		assert.Equal(t, h.Sum(msg), h.Sum(result.Forged))
+	}
+
+	h := NewToyHash(2)
+	_, err := h.SecondPreimageAttack(make([]byte, 4*ToyHashBlockSize), 4)
+	assert.Error(t, err)
+}
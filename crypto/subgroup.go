@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// DHOracle answers a public key with a message and its MAC under the shared
+// secret, like DHBob.Respond.
+type DHOracle func(h *big.Int) ([]byte, []byte)
+
+// SmallFactors returns the distinct prime factors of n below bound by trial
+// division.
+func SmallFactors(n *big.Int, bound int64) []*big.Int {
+	var factors []*big.Int
+	rest := new(big.Int).Set(n)
+	mod := new(big.Int)
+	for r := int64(2); r < bound && rest.Cmp(bigOne) > 0; r++ {
+		bigR := big.NewInt(r)
+		if mod.Mod(rest, bigR).Sign() != 0 {
+			continue
+		}
+		factors = append(factors, bigR)
+		for mod.Mod(rest, bigR).Sign() == 0 {
+			rest.Div(rest, bigR)
+		}
+	}
+	return factors
+}
+
+// ElementOfOrder returns a random element of order r mod p, for a prime r
+// dividing p-1.
+func ElementOfOrder(p, r *big.Int) (*big.Int, error) {
+	pMinusOne := new(big.Int).Sub(p, bigOne)
+	if new(big.Int).Mod(pMinusOne, r).Sign() != 0 {
+		return nil, fmt.Errorf("%v doesn't divide p-1", r)
+	}
+	cofactor := new(big.Int).Div(pMinusOne, r)
+	for {
+		h, err := rand.Int(rand.Reader, pMinusOne)
+		if err != nil {
+			return nil, err
+		}
+		h.Add(h, bigOne).Exp(h, cofactor, p)
+		if h.Cmp(bigOne) != 0 {
+			return h, nil
+		}
+	}
+}
+
+// SubgroupConfinementAttack recovers the private key of a DHOracle modulo
+// the small factors of j = (p-1)/q, the ones below bound. For each factor r
+// it sends an element h of order r, so the shared secret is one of the r
+// powers of h, and finds which one by checking the MAC. The residues x mod r
+// are combined with the CRT; it returns x mod R and R, the product of the
+// factors. When R > q, that's the key itself.
+// Link: https://cryptopals.com/sets/8/challenges/57
+func SubgroupConfinementAttack(group DHGroup, j *big.Int, bound int64, oracle DHOracle) (*big.Int, *big.Int, error) {
+	var residues, moduli []*big.Int
+	product := big.NewInt(1)
+	for _, r := range SmallFactors(j, bound) {
+		h, err := ElementOfOrder(group.P, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		msg, mac := oracle(h)
+		k, err := matchSubgroupMAC(group.P, h, r, msg, mac)
+		if err != nil {
+			return nil, nil, err
+		}
+		residues = append(residues, k)
+		moduli = append(moduli, r)
+		product.Mul(product, r)
+		if product.Cmp(group.Q) > 0 {
+			break
+		}
+	}
+	if len(moduli) == 0 {
+		return nil, nil, errors.New("no small factors")
+	}
+	return CRT(residues, moduli)
+}
+
+// matchSubgroupMAC finds the k in [0, r) for which h**k is the key of mac.
+func matchSubgroupMAC(p, h, r *big.Int, msg, mac []byte) (*big.Int, error) {
+	secret := big.NewInt(1)
+	for k := int64(0); big.NewInt(k).Cmp(r) < 0; k++ {
+		if hmac.Equal(mac, DHMAC(secret, msg)) {
+			return big.NewInt(k), nil
+		}
+		secret.Mul(secret, h).Mod(secret, p)
+	}
+	return nil, fmt.Errorf("no key in the subgroup of order %v matches", r)
+}
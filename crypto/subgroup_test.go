@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmallFactors(t *testing.T) {
+	n := big.NewInt(2 * 2 * 3 * 101 * 65537)
+	assert.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(101)}, SmallFactors(n, 1000))
+	assert.Empty(t, SmallFactors(big.NewInt(65537), 1000))
+}
+
+func TestElementOfOrder(t *testing.T) {
+	r := big.NewInt(7963)
+	h, err := ElementOfOrder(testDHGroup.P, r)
+	assert.NoError(t, err)
+	assert.NotEqual(t, bigOne, h)
+	assert.Equal(t, bigOne, new(big.Int).Exp(h, r, testDHGroup.P))
+
+	_, err = ElementOfOrder(testDHGroup.P, big.NewInt(7))
+	assert.Error(t, err)
+}
+
+func TestSubgroupConfinementAttack(t *testing.T) {
+	bob, err := NewDHBob(testDHGroup)
+	assert.NoError(t, err)
+	x, modulus, err := SubgroupConfinementAttack(testDHGroup, testDHJ, 1<<16, bob.Respond)
+	assert.NoError(t, err)
+	assert.True(t, modulus.Cmp(testDHGroup.Q) > 0)
+	assert.Equal(t, bob.priv.X, x)
+
+	_, _, err = SubgroupConfinementAttack(testDHGroup, big.NewInt(1), 1<<16, bob.Respond)
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// ErrCiphertextReplayed is returned by RSADecryptionServer when it's asked to
+// decrypt a ciphertext it has already decrypted.
+var ErrCiphertextReplayed = errors.New("ciphertext was already decrypted")
+
+// RSADecryptionServer decrypts textbook RSA ciphertexts, but only once each.
+// It remembers the hash of every ciphertext it has seen and refuses to
+// decrypt it again.
+type RSADecryptionServer struct {
+	priv *RSAPrivateKey
+	mu   sync.Mutex
+	seen map[[sha256.Size]byte]bool
+}
+
+// NewRSADecryptionServer makes a server that decrypts with priv.
+func NewRSADecryptionServer(priv *RSAPrivateKey) *RSADecryptionServer {
+	return &RSADecryptionServer{priv: priv, seen: make(map[[sha256.Size]byte]bool)}
+}
+
+// PublicKey returns the public key clients should encrypt to.
+func (s *RSADecryptionServer) PublicKey() *RSAPublicKey {
+	return &s.priv.RSAPublicKey
+}
+
+// Decrypt decrypts c unless it was decrypted before.
+// The ciphertext is reduced mod n before hashing, so c+n doesn't count as new.
+func (s *RSADecryptionServer) Decrypt(c *big.Int) (*big.Int, error) {
+	reduced := new(big.Int).Mod(c, s.priv.N)
+	hash := sha256.Sum256(reduced.Bytes())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen[hash] {
+		return nil, ErrCiphertextReplayed
+	}
+	s.seen[hash] = true
+	return s.priv.Decrypt(reduced), nil
+}
+
+// RecoverUnpaddedMessage decrypts c through an oracle that won't decrypt c
+// itself. The ciphertext is blinded as c' = s**e * c, which decrypts to s*m,
+// and the plaintext is unblinded by multiplying with the inverse of s.
+// Link: https://cryptopals.com/sets/6/challenges/41
+func RecoverUnpaddedMessage(pub *RSAPublicKey, c *big.Int, decrypt func(*big.Int) (*big.Int, error)) (*big.Int, error) {
+	var s, sInverse *big.Int
+	for sInverse == nil {
+		var err error
+		s, err = rand.Int(rand.Reader, new(big.Int).Sub(pub.N, bigTwo))
+		if err != nil {
+			return nil, err
+		}
+		s.Add(s, bigTwo)
+		sInverse = new(big.Int).ModInverse(s, pub.N)
+	}
+
+	blinded := new(big.Int).Mul(pub.Encrypt(s), c)
+	blinded.Mod(blinded, pub.N)
+	p, err := decrypt(blinded)
+	if err != nil {
+		return nil, err
+	}
+	m := new(big.Int).Mul(p, sInverse)
+	return m.Mod(m, pub.N), nil
+}
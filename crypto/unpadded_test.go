@@ -0,0 +1,58 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSADecryptionServer(t *testing.T) {
+	priv, err := GenerateRSAKey(512, 65537)
+	assert.NoError(t, err)
+	server := NewRSADecryptionServer(priv)
+	m := new(big.Int).SetBytes([]byte(`{time: 1356304276, social: '555-55-5555'}`))
+	c := server.PublicKey().Encrypt(m)
+
+	got, err := server.Decrypt(c)
+	assert.NoError(t, err)
+	assert.Equal(t, m, got)
+
+	// the same ciphertext, or the same one plus n, is refused
+	_, err = server.Decrypt(c)
+	assert.Equal(t, ErrCiphertextReplayed, err)
+	_, err = server.Decrypt(new(big.Int).Add(c, priv.N))
+	assert.Equal(t, ErrCiphertextReplayed, err)
+}
+
+func TestRecoverUnpaddedMessage(t *testing.T) {
+	priv, err := GenerateRSAKey(512, 65537)
+	assert.NoError(t, err)
+	server := NewRSADecryptionServer(priv)
+	m := new(big.Int).SetBytes([]byte(`{time: 1356304276, social: '555-55-5555'}`))
+	c := server.PublicKey().Encrypt(m)
+
+	// the victim already decrypted the message, so the server won't do it again
+	_, err = server.Decrypt(c)
+	assert.NoError(t, err)
+	_, err = server.Decrypt(c)
+	assert.Equal(t, ErrCiphertextReplayed, err)
+
+	var queried []*big.Int
+	decrypt := func(c *big.Int) (*big.Int, error) {
+		queried = append(queried, c)
+		return server.Decrypt(c)
+	}
+	got, err := RecoverUnpaddedMessage(server.PublicKey(), c, decrypt)
+	assert.NoError(t, err)
+	assert.Equal(t, m, got)
+	// the attack only went through because the server saw a new ciphertext
+	assert.Len(t, queried, 1)
+	assert.NotEqual(t, c, queried[0])
+
+	// and a server that refuses the blinded ciphertext stops the attack
+	_, err = RecoverUnpaddedMessage(server.PublicKey(), c, func(c *big.Int) (*big.Int, error) {
+		return nil, ErrCiphertextReplayed
+	})
+	assert.Equal(t, ErrCiphertextReplayed, err)
+}
@@ -0,0 +1,311 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// wangCondition is a condition of Wang's differential path on one bit of an
+// intermediate MD4 state. Bits are numbered from 1 like in the paper.
+type wangCondition struct {
+	bit  uint
+	kind int
+}
+
+const (
+	// the bit is 0
+	wangZero = iota
+	// the bit is 1
+	wangOne
+	// the bit equals the same bit of the state computed just before
+	wangPrev
+	// the bit differs from the same bit of the state computed just before
+	wangNotPrev
+)
+
+func (c wangCondition) mask() uint32 {
+	return 1 << (c.bit - 1)
+}
+
+// holds tells whether v satisfies the condition, prev being the state
+// computed just before v.
+func (c wangCondition) holds(v, prev uint32) bool {
+	m := c.mask()
+	switch c.kind {
+	case wangZero:
+		return v&m == 0
+	case wangOne:
+		return v&m != 0
+	case wangPrev:
+		return (v^prev)&m == 0
+	default:
+		return (v^prev)&m != 0
+	}
+}
+
+// fix sets the bit of v so the condition holds.
+func (c wangCondition) fix(v, prev uint32) uint32 {
+	if c.holds(v, prev) {
+		return v
+	}
+	return v ^ c.mask()
+}
+
+// Shorthands for the tables below.
+func wangBitZero(bit uint) wangCondition { return wangCondition{bit, wangZero} }
+func wangBitOne(bit uint) wangCondition  { return wangCondition{bit, wangOne} }
+func wangBitEq(bit uint) wangCondition   { return wangCondition{bit, wangPrev} }
+func wangBitNe(bit uint) wangCondition   { return wangCondition{bit, wangNotPrev} }
+
+// wangConditions are the sufficient conditions on the states of the first
+// two rounds, in the order they're computed: a1, d1, c1, b1, a2, ..., b4 for
+// round 1 then a5, d5, ... for round 2. Table 6 of Wang et al.
+var wangConditions = [][]wangCondition{
+	// round 1
+	{wangBitEq(7)},
+	{wangBitZero(7), wangBitEq(8), wangBitEq(11)},
+	{wangBitOne(7), wangBitOne(8), wangBitZero(11), wangBitEq(26)},
+	{wangBitOne(7), wangBitZero(8), wangBitZero(11), wangBitZero(26)},
+	{wangBitOne(8), wangBitOne(11), wangBitZero(26), wangBitEq(14)},
+	{
+		wangBitZero(14), wangBitEq(19), wangBitEq(20), wangBitEq(21),
+		wangBitEq(22), wangBitOne(26),
+	},
+	{
+		wangBitEq(13), wangBitZero(14), wangBitEq(15), wangBitZero(19),
+		wangBitZero(20), wangBitOne(21), wangBitZero(22),
+	},
+	{
+		wangBitOne(13), wangBitOne(14), wangBitZero(15), wangBitEq(17),
+		wangBitZero(19), wangBitZero(20), wangBitZero(21),
+		wangBitZero(22),
+	},
+	{
+		wangBitOne(13), wangBitOne(14), wangBitOne(15), wangBitZero(17),
+		wangBitZero(19), wangBitZero(20), wangBitZero(21),
+		wangBitEq(23), wangBitOne(22), wangBitEq(26),
+	},
+	{
+		wangBitOne(13), wangBitOne(14), wangBitOne(15), wangBitZero(17),
+		wangBitZero(20), wangBitOne(21), wangBitOne(22),
+		wangBitZero(23), wangBitOne(26), wangBitEq(30),
+	},
+	{
+		wangBitOne(17), wangBitZero(20), wangBitZero(21),
+		wangBitZero(22), wangBitZero(23), wangBitZero(26),
+		wangBitOne(30), wangBitEq(32),
+	},
+	{
+		wangBitZero(20), wangBitOne(21), wangBitOne(22), wangBitEq(23),
+		wangBitOne(26), wangBitZero(30), wangBitZero(32),
+	},
+	{
+		wangBitZero(23), wangBitZero(26), wangBitEq(27), wangBitEq(29),
+		wangBitOne(30), wangBitZero(32),
+	},
+	{
+		wangBitZero(23), wangBitZero(26), wangBitOne(27),
+		wangBitOne(29), wangBitZero(30), wangBitOne(32),
+	},
+	{
+		wangBitEq(19), wangBitOne(23), wangBitOne(26), wangBitZero(27),
+		wangBitZero(29), wangBitZero(30),
+	},
+	{
+		wangBitZero(19), wangBitEq(26), wangBitOne(27), wangBitOne(29),
+		wangBitZero(30),
+	},
+	// round 2
+	{wangBitOne(26), wangBitZero(27), wangBitOne(29), wangBitOne(32)},
+	{wangBitEq(19)},
+	{
+		wangBitEq(26), wangBitEq(27), wangBitEq(29), wangBitEq(30),
+		wangBitEq(32),
+	},
+	{wangBitEq(29), wangBitOne(30), wangBitZero(32)},
+	{wangBitOne(29), wangBitOne(32)},
+	// d6,29 = b5,29 is in wangD6Conditions
+	{},
+	{wangBitEq(29), wangBitNe(30), wangBitNe(32)},
+}
+
+// wangTargeted is a condition of round 2 compared with the state q[other]
+// rather than the previous one.
+type wangTargeted struct {
+	wangCondition
+	other int
+}
+
+// The conditions of a5 and d5 including those on c4 and b4, which
+// wangConditions leaves out, and the one of d6 on b5.
+var (
+	wangA5Conditions = []wangTargeted{
+		{wangBitEq(19), 18}, {wangBitOne(26), 19}, {wangBitZero(27), 19},
+		{wangBitOne(29), 19}, {wangBitOne(32), 19},
+	}
+	wangD5Conditions = []wangTargeted{
+		{wangBitEq(19), 20}, {wangBitEq(26), 19}, {wangBitEq(27), 19},
+		{wangBitEq(29), 19}, {wangBitEq(32), 19},
+	}
+	wangD6Conditions = []wangTargeted{{wangBitEq(29), 23}}
+)
+
+// wangTargetedConditions are the targeted conditions by step.
+var wangTargetedConditions = map[int][]wangTargeted{
+	16: wangA5Conditions,
+	17: wangD5Conditions,
+	21: wangD6Conditions,
+}
+
+// wangState holds the intermediate states of the first two rounds: q[0..3]
+// are a0, d0, c0, b0 and step i computes q[i+4] from q[i..i+3].
+type wangState [4 + 32]uint32
+
+func newWangState() *wangState {
+	q := &wangState{}
+	q[0], q[1], q[2], q[3] = MD4IV[0], MD4IV[3], MD4IV[2], MD4IV[1]
+	return q
+}
+
+// round1 computes round 1 step i.
+func (q *wangState) round1(i int, x [16]uint32) uint32 {
+	return MD4Round1(q[i], q[i+3], q[i+2], q[i+1], x[i], md4Round1Shifts[i%4])
+}
+
+// round2 computes round 2 step i, with i counted from 16.
+func (q *wangState) round2(i int, x [16]uint32) uint32 {
+	return MD4Round2(q[i], q[i+3], q[i+2], q[i+1], x[md4Round2Order[i-16]], md4Round2Shifts[i%4])
+}
+
+// word returns the message word that makes round 1 step i produce q[i+4].
+func (q *wangState) word(i int) uint32 {
+	return bits.RotateLeft32(q[i+4], -md4Round1Shifts[i%4]) - q[i] - MD4F(q[i+3], q[i+2], q[i+1])
+}
+
+// WangModify applies Wang's message modification to a block: every round 1
+// state is corrected to meet its conditions and the word that produces it is
+// recomputed, then the conditions on a5 and d5 of round 2 are fixed through
+// m0 and m4, recomputing the round 1 words that depend on them.
+// Link: https://cryptopals.com/sets/7/challenges/55
+func WangModify(x [16]uint32) [16]uint32 {
+	q := newWangState()
+
+	// round 1, single-step modification
+	for i := 0; i < 16; i++ {
+		v := q.round1(i, x)
+		for _, c := range wangConditions[i] {
+			v = c.fix(v, q[i+3])
+		}
+		q[i+4] = v
+		x[i] = q.word(i)
+	}
+
+	// a5 = R2(a4, b4, c4, d4, m0, 3): bit j of a5 is bit j-3 of the sum
+	// before the rotation, so adding or subtracting 2**(j-3) to m0 flips it
+	// without a carry. The same change of m0 goes into a1, whose high bits
+	// have no conditions, and m1..m4 are recomputed to keep d1..a2.
+	for _, c := range wangA5Conditions {
+		if v := q.round2(16, x); !c.holds(v, q[c.other]) {
+			x[0] = wangNudge(x[0], v, c.bit, 3)
+			q[4] = q.round1(0, x)
+			for i := 1; i < 5; i++ {
+				x[i] = q.word(i)
+			}
+		}
+	}
+	q[20] = q.round2(16, x)
+
+	// d5 = R2(d4, a5, b4, c4, m4, 5): the same with 2**(j-5) and m4, which
+	// lands on bit j-2 of a2. A carry there may break a condition of a2 or
+	// d2, then the change is undone.
+	for _, c := range wangD5Conditions {
+		if v := q.round2(17, x); !c.holds(v, q[c.other]) {
+			saved, a2 := x, q[8]
+			x[4] = wangNudge(x[4], v, c.bit, 5)
+			q[8] = q.round1(4, x)
+			for i := 5; i < 9; i++ {
+				x[i] = q.word(i)
+			}
+			if !wangHolds(q, 4) || !wangHolds(q, 5) {
+				x, q[8] = saved, a2
+			}
+		}
+	}
+	return x
+}
+
+// wangNudge changes the message word m of a step whose output v is rotated
+// by s so that bit of v flips without a carry.
+func wangNudge(m, v uint32, bit uint, s int) uint32 {
+	d := bits.RotateLeft32(1<<(bit-1), -s)
+	if v&(1<<(bit-1)) == 0 {
+		return m + d
+	}
+	return m - d
+}
+
+// wangHolds tells whether the state of step i meets its conditions.
+func wangHolds(q *wangState, i int) bool {
+	for _, c := range wangConditions[i] {
+		if !c.holds(q[i+4], q[i+3]) {
+			return false
+		}
+	}
+	for _, c := range wangTargetedConditions[i] {
+		if !c.holds(q[i+4], q[c.other]) {
+			return false
+		}
+	}
+	return true
+}
+
+// WangDifferential returns the block that should collide with x:
+// m1 + 2**31, m2 + 2**31 - 2**28 and m12 - 2**16.
+func WangDifferential(x [16]uint32) [16]uint32 {
+	x[1] += 1 << 31
+	x[2] += 1<<31 - 1<<28
+	x[12] -= 1 << 16
+	return x
+}
+
+// wangSatisfied counts how many of the steps, in computation order, meet all
+// their conditions before the first one that doesn't.
+func wangSatisfied(x [16]uint32) int {
+	q := newWangState()
+	for i := range wangConditions {
+		if i < 16 {
+			q[i+4] = q.round1(i, x)
+		} else {
+			q[i+4] = q.round2(i, x)
+		}
+		if !wangHolds(q, i) {
+			return i
+		}
+	}
+	return len(wangConditions)
+}
+
+// FindMD4Collision searches for two different 64-byte blocks with the same
+// MD4 compression from the standard IV, so that any message starting with
+// either has the same MD4 hash. Random blocks are modified with WangModify,
+// which makes a pair collide with a probability around 2**-20 rather than
+// 2**-122. It gives up after maxTries blocks and returns the number of tries.
+// Link: https://cryptopals.com/sets/7/challenges/55
+func FindMD4Collision(maxTries int) ([]byte, []byte, int, error) {
+	random := bufio.NewReaderSize(rand.Reader, 64*1024)
+	block := make([]byte, 64)
+	for tries := 1; tries <= maxTries; tries++ {
+		if _, err := io.ReadFull(random, block); err != nil {
+			return nil, nil, tries, err
+		}
+		x := WangModify(MD4Words(block))
+		x2 := WangDifferential(x)
+		if x != x2 && MD4Compress(MD4IV, x) == MD4Compress(MD4IV, x2) {
+			return MD4Block(x), MD4Block(x2), tries, nil
+		}
+	}
+	return nil, nil, maxTries, fmt.Errorf("no MD4 collision in %d tries", maxTries)
+}
@@ -0,0 +1,129 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWangModify(t *testing.T) {
+	block := make([]byte, 64)
+	for i := 0; i < 1000; i++ {
+		_, err := rand.Read(block)
+		assert.NoError(t, err)
+		x := WangModify(MD4Words(block))
+		// every round 1 condition holds
+		assert.True(t, wangSatisfied(x) >= 16)
+	}
+}
+
+// wangTable6 is Table 6 of Wang et al. as printed, one state per line, with
+// b4,26 = c4,26 = 1 split in two.
+var wangTable6 = []string{
+	"a1,7 = b0,7",
+	"d1,7 = 0, d1,8 = a1,8, d1,11 = a1,11",
+	"c1,7 = 1, c1,8 = 1, c1,11 = 0, c1,26 = d1,26",
+	"b1,7 = 1, b1,8 = 0, b1,11 = 0, b1,26 = 0",
+	"a2,8 = 1, a2,11 = 1, a2,26 = 0, a2,14 = b1,14",
+	"d2,14 = 0, d2,19 = a2,19, d2,20 = a2,20, d2,21 = a2,21, d2,22 = a2,22, d2,26 = 1",
+	"c2,13 = d2,13, c2,14 = 0, c2,15 = d2,15, c2,19 = 0, c2,20 = 0, c2,21 = 1, c2,22 = 0",
+	"b2,13 = 1, b2,14 = 1, b2,15 = 0, b2,17 = c2,17, b2,19 = 0, b2,20 = 0, b2,21 = 0, b2,22 = 0",
+	"a3,13 = 1, a3,14 = 1, a3,15 = 1, a3,17 = 0, a3,19 = 0, a3,20 = 0, a3,21 = 0, a3,23 = b2,23, a3,22 = 1, a3,26 = b2,26",
+	"d3,13 = 1, d3,14 = 1, d3,15 = 1, d3,17 = 0, d3,20 = 0, d3,21 = 1, d3,22 = 1, d3,23 = 0, d3,26 = 1, d3,30 = a3,30",
+	"c3,17 = 1, c3,20 = 0, c3,21 = 0, c3,22 = 0, c3,23 = 0, c3,26 = 0, c3,30 = 1, c3,32 = d3,32",
+	"b3,20 = 0, b3,21 = 1, b3,22 = 1, b3,23 = c3,23, b3,26 = 1, b3,30 = 0, b3,32 = 0",
+	"a4,23 = 0, a4,26 = 0, a4,27 = b3,27, a4,29 = b3,29, a4,30 = 1, a4,32 = 0",
+	"d4,23 = 0, d4,26 = 0, d4,27 = 1, d4,29 = 1, d4,30 = 0, d4,32 = 1",
+	"c4,19 = d4,19, c4,23 = 1, c4,26 = 1, c4,27 = 0, c4,29 = 0, c4,30 = 0",
+	"b4,19 = 0, b4,26 = c4,26, b4,26 = 1, b4,27 = 1, b4,29 = 1, b4,30 = 0",
+	"a5,19 = c4,19, a5,26 = 1, a5,27 = 0, a5,29 = 1, a5,32 = 1",
+	"d5,19 = a5,19, d5,26 = b4,26, d5,27 = b4,27, d5,29 = b4,29, d5,32 = b4,32",
+	"c5,26 = d5,26, c5,27 = d5,27, c5,29 = d5,29, c5,30 = d5,30, c5,32 = d5,32",
+	"b5,29 = c5,29, b5,30 = 1, b5,32 = 0",
+	"a6,29 = 1, a6,32 = 1",
+	"d6,29 = b5,29",
+	"c6,29 = d6,29, c6,30 = d6,30 + 1, c6,32 = d6,32 + 1",
+}
+
+// wangTableBit parses a state and bit like "d5,26" into the index of the
+// state in a wangState and the bit.
+func wangTableBit(t *testing.T, s string) (int, uint) {
+	parts := strings.Split(s, ",")
+	n, err := strconv.Atoi(parts[0][1:])
+	assert.NoError(t, err)
+	bit, err := strconv.Atoi(parts[1])
+	assert.NoError(t, err)
+	return 4*n + strings.IndexByte("adcb", parts[0][0]), uint(bit)
+}
+
+func TestWangConditions(t *testing.T) {
+	words := make([]byte, 4*len(wangTable6))
+	for try := 0; try < 100; try++ {
+		// random states with the bits of Table 6 set one by one
+		_, err := rand.Read(words)
+		assert.NoError(t, err)
+		q := newWangState()
+		for i, row := range wangTable6 {
+			q[i+4] = binary.LittleEndian.Uint32(words[4*i:])
+			for _, cond := range strings.Split(row, ", ") {
+				sides := strings.Split(cond, " = ")
+				j, bit := wangTableBit(t, sides[0])
+				assert.Equal(t, i+4, j)
+				var v uint32
+				switch value := strings.TrimSuffix(sides[1], " + 1"); value {
+				case "0", "1":
+					v = uint32(value[0] - '0')
+				default:
+					k, other := wangTableBit(t, value)
+					v = q[k] >> (other - 1) & 1
+				}
+				if strings.HasSuffix(sides[1], " + 1") {
+					v ^= 1
+				}
+				q[j] = q[j]&^(1<<(bit-1)) | v<<(bit-1)
+			}
+		}
+		for i := range wangConditions {
+			assert.True(t, wangHolds(q, i), "step %d", i)
+		}
+
+		// the table forces b5,29 = 1 = a6,29, but d6 is checked against b5
+		q[23] ^= 1 << 28
+		q[25] ^= 1 << 28
+		assert.False(t, wangHolds(q, 19))
+		assert.True(t, wangHolds(q, 21))
+	}
+}
+
+func TestFindMD4Collision(t *testing.T) {
+	m1, m2, tries, err := FindMD4Collision(1 << 26)
+	assert.NoError(t, err)
+	assert.True(t, tries > 0)
+	assert.False(t, bytes.Equal(m1, m2))
+	assert.Equal(t, MD4Sum(m1), MD4Sum(m2))
+
+	// the same suffix keeps the collision
+	suffix := []byte("YELLOW SUBMARINE")
+	assert.Equal(t, MD4Sum(append(m1, suffix...)), MD4Sum(append(m2, suffix...)))
+
+	_, _, _, err = FindMD4Collision(1)
+	assert.Error(t, err)
+}
+
+// BenchmarkWangModify measures the search rate, in blocks tried per second.
+func BenchmarkWangModify(b *testing.B) {
+	block := make([]byte, 64)
+	for i := 0; i < b.N; i++ {
+		if _, err := rand.Read(block); err != nil {
+			b.Fatal(err)
+		}
+		x := WangModify(MD4Words(block))
+		MD4Compress(MD4IV, x)
+		MD4Compress(MD4IV, WangDifferential(x))
+	}
+}
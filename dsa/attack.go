@@ -0,0 +1,181 @@
+package dsa
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// ErrKeyNotFound is returned when an attack runs out of candidates.
+var ErrKeyNotFound = errors.New("private key not found")
+
+// KeyFromNonce recovers the private key from a signature whose nonce k is
+// known: x = (s*k - H(m)) * r**-1 mod q. It fails when r = 0 mod q, which
+// gives no information on x.
+func KeyFromNonce(params Parameters, hash []byte, sig *Signature, k *big.Int) (*big.Int, error) {
+	q := params.Q
+	rInverse := new(big.Int).Mod(sig.R, q)
+	if rInverse.ModInverse(rInverse, q) == nil {
+		return nil, ErrZeroSignature
+	}
+	x := new(big.Int).Mul(sig.S, k)
+	x.Sub(x, new(big.Int).SetBytes(hash))
+	x.Mul(x, rInverse)
+	return x.Mod(x, q), nil
+}
+
+// BruteForceNonce recovers the private key of a signature made with a nonce
+// in [0, maxNonce]. Candidate nonces are checked against r first, which only
+// costs one modular multiplication each.
+// Link: https://cryptopals.com/sets/6/challenges/43
+func BruteForceNonce(pub *PublicKey, hash []byte, sig *Signature, maxNonce int64) (*PrivateKey, error) {
+	p, q := pub.P, pub.Q
+	gk := big.NewInt(1)
+	r := new(big.Int)
+	for k := int64(0); k <= maxNonce; k++ {
+		if r.Mod(gk, q).Cmp(sig.R) == 0 {
+			x, err := KeyFromNonce(pub.Parameters, hash, sig, big.NewInt(k))
+			if err != nil {
+				return nil, ErrKeyNotFound
+			}
+			priv := NewPrivateKey(pub.Parameters, x)
+			if priv.Y.Cmp(pub.Y) == 0 {
+				return priv, nil
+			}
+		}
+		gk.Mul(gk, pub.G)
+		gk.Mod(gk, p)
+	}
+	return nil, ErrKeyNotFound
+}
+
+// SignedMessage is a message with its DSA signature and digest,
+// as listed in the Cryptopals 44.txt file.
+type SignedMessage struct {
+	Msg  string
+	Hash []byte
+	Sig  Signature
+}
+
+// ParseSignedMessages reads a file of signed messages. Each message takes
+// four lines:
+//
+//	msg: <text>
+//	s: <decimal>
+//	r: <decimal>
+//	m: <hex digest>
+func ParseSignedMessages(filename string) ([]SignedMessage, error) {
+	var lines []string
+
+	// read file
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var messages []SignedMessage
+	var fields [4]string
+	keys := [4]string{"msg: ", "s: ", "r: ", "m: "}
+	n := 0
+	for i, line := range lines {
+		if n == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, keys[n]) {
+			return nil, fmt.Errorf("%s:%d: expected %q line", filename, i+1, strings.TrimSpace(keys[n]))
+		}
+		fields[n] = strings.TrimPrefix(line, keys[n])
+		n++
+		if n < len(keys) {
+			continue
+		}
+		n = 0
+
+		s, ok := new(big.Int).SetString(strings.TrimSpace(fields[1]), 10)
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: bad s value %q", filename, i-1, fields[1])
+		}
+		r, ok := new(big.Int).SetString(strings.TrimSpace(fields[2]), 10)
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: bad r value %q", filename, i, fields[2])
+		}
+		// the digests aren't zero padded, so go through big.Int
+		m, ok := new(big.Int).SetString(strings.TrimSpace(fields[3]), 16)
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: bad m value %q", filename, i+1, fields[3])
+		}
+		messages = append(messages, SignedMessage{fields[0], m.Bytes(), Signature{r, s}})
+	}
+	if n != 0 {
+		return nil, fmt.Errorf("%s: truncated message at end of file", filename)
+	}
+	return messages, nil
+}
+
+// RecoverFromRepeatedNonce looks for two signatures made with the same nonce
+// and recovers the private key from them. Equal nonces give equal r, and
+// then k = (m1 - m2) / (s1 - s2) mod q.
+// Link: https://cryptopals.com/sets/6/challenges/44
+func RecoverFromRepeatedNonce(pub *PublicKey, messages []SignedMessage) (*PrivateKey, error) {
+	q := pub.Q
+	byR := make(map[string]int)
+	for j, msg := range messages {
+		i, ok := byR[msg.Sig.R.String()]
+		if !ok {
+			byR[msg.Sig.R.String()] = j
+			continue
+		}
+
+		ds := new(big.Int).Sub(messages[i].Sig.S, msg.Sig.S)
+		ds.Mod(ds, q)
+		dsInverse := new(big.Int).ModInverse(ds, q)
+		if dsInverse == nil {
+			// same signature twice
+			continue
+		}
+		k := new(big.Int).SetBytes(messages[i].Hash)
+		k.Sub(k, new(big.Int).SetBytes(msg.Hash))
+		k.Mul(k, dsInverse)
+		k.Mod(k, q)
+
+		x, err := KeyFromNonce(pub.Parameters, msg.Hash, &msg.Sig, k)
+		if err != nil {
+			continue
+		}
+		priv := NewPrivateKey(pub.Parameters, x)
+		if priv.Y.Cmp(pub.Y) == 0 {
+			return priv, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// ForgeMagicSignature makes a signature that SloppyVerify accepts for any
+// message, given a key whose generator was tampered to g = p+1 (which is 1
+// mod p). With r = (y**z mod p) mod q and s = r/z mod q, verification
+// computes y**(r*s**-1) = y**z for any z that is invertible mod q.
+// Link: https://cryptopals.com/sets/6/challenges/45
+func ForgeMagicSignature(pub *PublicKey, z *big.Int) (*Signature, error) {
+	p, q := pub.P, pub.Q
+	s := new(big.Int).Mod(z, q)
+	if s.ModInverse(s, q) == nil {
+		return nil, errors.New("z must be invertible mod q")
+	}
+	r := new(big.Int).Exp(pub.Y, z, p)
+	r.Mod(r, q)
+	s.Mul(s, r)
+	s.Mod(s, q)
+	return &Signature{r, s}, nil
+}
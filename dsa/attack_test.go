@@ -0,0 +1,202 @@
+package dsa
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fromDecimal(s string) *big.Int {
+	x, _ := new(big.Int).SetString(s, 10)
+	return x
+}
+
+func TestKeyFromNonce(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	hash := Hash([]byte("hi mom"))
+	k := big.NewInt(424242)
+	sig, err := Sign(priv, hash, func(q *big.Int) (*big.Int, error) { return k, nil })
+	assert.NoError(t, err)
+	x, err := KeyFromNonce(priv.Parameters, hash, sig, k)
+	assert.NoError(t, err)
+	assert.Equal(t, priv.X, x)
+
+	// r = 0 has no inverse
+	_, err = KeyFromNonce(priv.Parameters, hash, &Signature{R: new(big.Int).Set(priv.Q), S: sig.S}, k)
+	assert.Equal(t, ErrZeroSignature, err)
+}
+
+func TestBruteForceNonce(t *testing.T) {
+	pub := &PublicKey{
+		Parameters: DefaultParameters,
+		Y:          fromHex("84ad4719d044495496a3201c8ff484feb45b962e7302e56a392aee4abab3e4bdebf2955b4736012f21a08084056b19bcd7fee56048e004e44984e2f411788efdc837a0d2e5abb7b555039fd243ac01f0fb2ed1dec568280ce678e931868d23eb095fde9d3779191b8c0299d6e07bbb283e6633451e535c45513b2d33c99ea17"),
+	}
+	msg := "For those that envy a MC it can be hazardous to your health\nSo be friendly, a matter of life and death, just like a etch-a-sketch\n"
+	hash := Hash([]byte(msg))
+	assert.Equal(t, "d2d0714f014a9784047eaeccf956520045c45265", hex.EncodeToString(hash))
+	sig := &Signature{
+		R: fromDecimal("548099063082341131477253921760299949438196259240"),
+		S: fromDecimal("857042759984254168557880549501802188789837994940"),
+	}
+
+	priv, err := BruteForceNonce(pub, hash, sig, 1<<16)
+	assert.NoError(t, err)
+	fingerprint := sha1.Sum([]byte(priv.X.Text(16)))
+	assert.Equal(t, "0954edd5e0afe5542a4adf012611a91912a3ec16", hex.EncodeToString(fingerprint[:]))
+
+	_, err = BruteForceNonce(pub, hash, sig, 100)
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	// with g = 0 the sloppy signer gives r = 0, which k = 1 matches but
+	// doesn't lead to the key
+	params := DefaultParameters
+	params.G = big.NewInt(0)
+	priv, err = GenerateKey(params)
+	assert.NoError(t, err)
+	sig, err = SloppySign(priv, hash, RandomNonce)
+	assert.NoError(t, err)
+	_, err = BruteForceNonce(&priv.PublicKey, hash, sig, 100)
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+// writeSignedMessages signs the messages and writes them in the 44.txt format.
+// The nonces are drawn from a tiny pool so some of them repeat.
+func writeSignedMessages(t *testing.T, priv *PrivateKey, messages []string) string {
+	file, err := ioutil.TempFile("", "signed")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	for i, msg := range messages {
+		k := big.NewInt(int64(1000 + i%3))
+		hash := Hash([]byte(msg))
+		sig, err := Sign(priv, hash, func(q *big.Int) (*big.Int, error) { return k, nil })
+		assert.NoError(t, err)
+		m := new(big.Int).SetBytes(hash)
+		fmt.Fprintf(file, "msg: %s\ns: %v\nr: %v\nm: %x\n", msg, sig.S, sig.R, m)
+	}
+	return file.Name()
+}
+
+func TestParseSignedMessages(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	messages := []string{"Listen for me, you better listen for me now. ", "Pure black people mon is all I mon know. "}
+	filename := writeSignedMessages(t, priv, messages)
+	defer os.Remove(filename)
+
+	parsed, err := ParseSignedMessages(filename)
+	assert.NoError(t, err)
+	assert.Len(t, parsed, 2)
+	for i, msg := range parsed {
+		assert.Equal(t, messages[i], msg.Msg)
+		assert.Equal(t, new(big.Int).SetBytes(Hash([]byte(messages[i]))), new(big.Int).SetBytes(msg.Hash))
+		assert.True(t, Verify(&priv.PublicKey, msg.Hash, &msg.Sig))
+	}
+
+	// a truncated record is an error
+	file, err := ioutil.TempFile("", "signed")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	fmt.Fprintf(file, "msg: hello\ns: 1\nr: 2\n")
+	file.Close()
+	_, err = ParseSignedMessages(file.Name())
+	assert.Error(t, err)
+
+	_, err = ParseSignedMessages("does-not-exist.txt")
+	assert.Error(t, err)
+}
+
+func TestRecoverFromRepeatedNonce(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	messages := []string{
+		"Listen for me, you better listen for me now. ",
+		"Pure black people mon is all I mon know. ",
+		"Yeah me shoes a an tear up an' now me toes is a show a ",
+		"Where me a born in are de one Toronto, so ",
+	}
+	filename := writeSignedMessages(t, priv, messages)
+	defer os.Remove(filename)
+
+	parsed, err := ParseSignedMessages(filename)
+	assert.NoError(t, err)
+	recovered, err := RecoverFromRepeatedNonce(&priv.PublicKey, parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, priv.X, recovered.X)
+
+	// without a repeated nonce there's nothing to find
+	_, err = RecoverFromRepeatedNonce(&priv.PublicKey, parsed[:3])
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestRecoverFromRepeatedNonce44(t *testing.T) {
+	pub := &PublicKey{
+		Parameters: DefaultParameters,
+		Y:          fromHex("2d026f4bf30195ede3a088da85e398ef869611d0f68f0713d51c9c1a3a26c95105d915e2d8cdf26d056b86b8a7b85519b1c23cc3ecdc6062650462e3063bd179c2a6581519f674a61f1d89a1fff27171ebc1b93d4dc57bceb7ae2430f98a6a4d83d8279ee65d71c1203d2c96d65ebbf7cce9d32971c3de5084cce04a2e147821"),
+	}
+	parsed, err := ParseSignedMessages("44.txt")
+	assert.NoError(t, err)
+	assert.Len(t, parsed, 11)
+	for _, msg := range parsed {
+		assert.Equal(t, Hash([]byte(msg.Msg)), msg.Hash, msg.Msg)
+		assert.True(t, Verify(pub, msg.Hash, &msg.Sig), msg.Msg)
+	}
+
+	priv, err := RecoverFromRepeatedNonce(pub, parsed)
+	assert.NoError(t, err)
+	fingerprint := sha1.Sum([]byte(priv.X.Text(16)))
+	assert.Equal(t, "ca8f6f7c66fa362d40760d135b763eb8527d3d52", hex.EncodeToString(fingerprint[:]))
+}
+
+func TestZeroGenerator(t *testing.T) {
+	params := DefaultParameters
+	params.G = big.NewInt(0)
+	priv, err := GenerateKey(params)
+	assert.NoError(t, err)
+
+	// the careful signer notices r = 0, the sloppy one doesn't
+	hash := Hash([]byte("Hello, world"))
+	_, err = Sign(priv, hash, RandomNonce)
+	assert.Equal(t, ErrZeroSignature, err)
+	sig, err := SloppySign(priv, hash, RandomNonce)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), sig.R.Int64())
+
+	// a signature with r = 0 is good for every message under the sloppy verifier
+	for _, msg := range []string{"Hello, world", "Goodbye, world"} {
+		hash := Hash([]byte(msg))
+		assert.True(t, SloppyVerify(&priv.PublicKey, hash, sig), msg)
+		assert.False(t, Verify(&priv.PublicKey, hash, sig), msg)
+	}
+}
+
+func TestForgeMagicSignature(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	tampered := priv.PublicKey
+	tampered.G = new(big.Int).Add(tampered.P, bigOne)
+
+	for i, msg := range []string{"Hello, world", "Goodbye, world"} {
+		hash := Hash([]byte(msg))
+		sig, err := ForgeMagicSignature(&tampered, big.NewInt(int64(i+2)))
+		assert.NoError(t, err)
+		assert.True(t, SloppyVerify(&tampered, hash, sig), msg)
+		assert.False(t, Verify(&tampered, hash, sig), msg)
+		// the forgery doesn't carry over to the honest parameters
+		assert.False(t, SloppyVerify(&priv.PublicKey, hash, sig), msg)
+	}
+
+	// z = 0 mod q has no inverse
+	for _, z := range []*big.Int{big.NewInt(0), tampered.Q} {
+		sig, err := ForgeMagicSignature(&tampered, z)
+		assert.Error(t, err)
+		assert.Nil(t, sig)
+	}
+}
@@ -0,0 +1,168 @@
+package dsa
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"errors"
+	"math/big"
+)
+
+// Parameters are the DSA group parameters: q divides p-1 and g generates the
+// subgroup of order q.
+type Parameters struct {
+	P, Q, G *big.Int
+}
+
+// PublicKey is a DSA public key y = g**x mod p.
+type PublicKey struct {
+	Parameters
+	Y *big.Int
+}
+
+// PrivateKey is a DSA private key.
+type PrivateKey struct {
+	PublicKey
+	X *big.Int
+}
+
+// Signature is a DSA signature.
+type Signature struct {
+	R, S *big.Int
+}
+
+// NonceSource produces the per-signature secret k in [1, q).
+// It's injectable so that bad nonces can be simulated.
+type NonceSource func(q *big.Int) (*big.Int, error)
+
+// Errors returned by signing and parameter validation.
+var (
+	ErrZeroSignature     = errors.New("nonce produced a zero signature component")
+	ErrInvalidParameters = errors.New("g doesn't generate a subgroup of order q")
+)
+
+var bigOne = big.NewInt(1)
+
+func fromHex(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("Error: bad hex constant " + s)
+	}
+	return x
+}
+
+// DefaultParameters are the parameters used by the Cryptopals challenges.
+var DefaultParameters = Parameters{
+	P: fromHex("800000000000000089e1855218a0e7dac38136ffafa72eda7859f2171e25e65eac698c1702578b07dc2a1076da241c76c62d374d8389ea5aeffd3226a0530cc565f3bf6b50929139ebeac04f48c3c84afb796d61e5a4f9a8fda812ab59494232c7d2b4deb50aa18ee9e132bfa85ac4374d7f9091abc3d015efc871a584471bb1"),
+	Q: fromHex("f4f47f05794b256174bba6e9b396a7707e563c5b"),
+	G: fromHex("5958c9d3898b224b12672c0b98e06c60df923cb8bc999d119458fef538b8fa4046c8db53039db620c094c9fa077ef389b5322a559946a71903f990f1f7e0e025e2d7f7cf494aff1a0470f5b64c36b625a097f1651fe775323556fe00b3608c887892878480e99041be601a62166ca6894bdd41a7054ec89f756ba9fc95302291"),
+}
+
+// Hash is the message digest used with these keys, SHA-1 as in FIPS 186.
+func Hash(msg []byte) []byte {
+	digest := sha1.Sum(msg)
+	return digest[:]
+}
+
+// RandomNonce draws k uniformly from [1, q).
+func RandomNonce(q *big.Int) (*big.Int, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(q, bigOne))
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, bigOne), nil
+}
+
+// GenerateKey makes a key pair for the parameters.
+func GenerateKey(params Parameters) (*PrivateKey, error) {
+	x, err := RandomNonce(params.Q)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(params, x), nil
+}
+
+// NewPrivateKey makes the key pair with private exponent x.
+func NewPrivateKey(params Parameters, x *big.Int) *PrivateKey {
+	y := new(big.Int).Exp(params.G, x, params.P)
+	return &PrivateKey{PublicKey{params, y}, x}
+}
+
+// Sign signs the digest of a message with a nonce from the source:
+// r = (g**k mod p) mod q and s = k**-1 (H(m) + x*r) mod q.
+func Sign(priv *PrivateKey, hash []byte, nonce NonceSource) (*Signature, error) {
+	sig, err := SloppySign(priv, hash, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if sig.R.Sign() == 0 || sig.S.Sign() == 0 {
+		return nil, ErrZeroSignature
+	}
+	return sig, nil
+}
+
+// SloppySign is Sign without the check for zero r or s, so it happily signs
+// with tampered parameters like g = 0.
+func SloppySign(priv *PrivateKey, hash []byte, nonce NonceSource) (*Signature, error) {
+	p, q := priv.P, priv.Q
+	k, err := nonce(q)
+	if err != nil {
+		return nil, err
+	}
+	kInverse := new(big.Int).ModInverse(k, q)
+	if kInverse == nil {
+		return nil, ErrZeroSignature
+	}
+
+	r := new(big.Int).Exp(priv.G, k, p)
+	r.Mod(r, q)
+	s := new(big.Int).Mul(priv.X, r)
+	s.Add(s, new(big.Int).SetBytes(hash))
+	s.Mul(s, kInverse)
+	s.Mod(s, q)
+	return &Signature{r, s}, nil
+}
+
+// Validate checks that g is a proper generator of the order q subgroup:
+// 1 < g < p and g**q = 1 mod p.
+func (params Parameters) Validate() error {
+	if params.G.Cmp(bigOne) <= 0 || params.G.Cmp(params.P) >= 0 {
+		return ErrInvalidParameters
+	}
+	if new(big.Int).Exp(params.G, params.Q, params.P).Cmp(bigOne) != 0 {
+		return ErrInvalidParameters
+	}
+	return nil
+}
+
+// Verify checks the signature of a message digest. It rejects keys with
+// tampered parameters and signature components outside of (0, q).
+func Verify(pub *PublicKey, hash []byte, sig *Signature) bool {
+	q := pub.Q
+	if pub.Validate() != nil {
+		return false
+	}
+	if sig.R.Sign() <= 0 || sig.R.Cmp(q) >= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(q) >= 0 {
+		return false
+	}
+	return SloppyVerify(pub, hash, sig)
+}
+
+// SloppyVerify checks the signature of a message digest against whatever
+// parameters come with the key, and doesn't range check r and s.
+func SloppyVerify(pub *PublicKey, hash []byte, sig *Signature) bool {
+	p, q := pub.P, pub.Q
+	w := new(big.Int).ModInverse(sig.S, q)
+	if w == nil {
+		return false
+	}
+	u1 := new(big.Int).SetBytes(hash)
+	u1.Mul(u1, w)
+	u1.Mod(u1, q)
+	u2 := new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, q)
+	v := new(big.Int).Exp(pub.G, u1, p)
+	v.Mul(v, new(big.Int).Exp(pub.Y, u2, p))
+	v.Mod(v, p)
+	v.Mod(v, q)
+	return v.Cmp(sig.R) == 0
+}
@@ -0,0 +1,56 @@
+package dsa
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultParameters(t *testing.T) {
+	params := DefaultParameters
+	assert.True(t, params.P.ProbablyPrime(20))
+	assert.True(t, params.Q.ProbablyPrime(20))
+	pMinusOne := new(big.Int).Sub(params.P, bigOne)
+	assert.Equal(t, int64(0), new(big.Int).Mod(pMinusOne, params.Q).Int64())
+	assert.Equal(t, bigOne, new(big.Int).Exp(params.G, params.Q, params.P))
+}
+
+func TestSignVerify(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	hash := Hash([]byte("Cooking MC's like a pound of bacon"))
+
+	sig, err := Sign(priv, hash, RandomNonce)
+	assert.NoError(t, err)
+	assert.True(t, Verify(&priv.PublicKey, hash, sig))
+	assert.False(t, Verify(&priv.PublicKey, Hash([]byte("Cooking MC's like a pound of tofu")), sig))
+
+	other, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	assert.False(t, Verify(&other.PublicKey, hash, sig))
+
+	// out of range signatures are rejected
+	assert.False(t, Verify(&priv.PublicKey, hash, &Signature{big.NewInt(0), sig.S}))
+	assert.False(t, Verify(&priv.PublicKey, hash, &Signature{sig.R, new(big.Int).Add(sig.S, priv.Q)}))
+
+	// an injected nonce is used as is
+	fixed := func(q *big.Int) (*big.Int, error) { return big.NewInt(12345), nil }
+	sig1, err := Sign(priv, hash, fixed)
+	assert.NoError(t, err)
+	sig2, err := Sign(priv, Hash([]byte("another message")), fixed)
+	assert.NoError(t, err)
+	assert.Equal(t, sig1.R, sig2.R)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, DefaultParameters.Validate())
+
+	tampered := DefaultParameters
+	tampered.G = big.NewInt(0)
+	assert.Equal(t, ErrInvalidParameters, tampered.Validate())
+	tampered.G = new(big.Int).Add(DefaultParameters.P, bigOne)
+	assert.Equal(t, ErrInvalidParameters, tampered.Validate())
+	tampered.G = big.NewInt(2)
+	assert.Equal(t, ErrInvalidParameters, tampered.Validate())
+}
@@ -0,0 +1,95 @@
+package ecc
+
+import (
+	"errors"
+	"math/big"
+
+	"gosano/lattice"
+)
+
+// BiasedNonce makes a NonceSource whose nonces have their low bits set to
+// zero, like a signer that leaks or fixes part of k.
+func BiasedNonce(bits uint) NonceSource {
+	return func(n *big.Int) (*big.Int, error) {
+		for {
+			k, err := RandomNonce(n)
+			if err != nil {
+				return nil, err
+			}
+			k.Rsh(k, bits).Lsh(k, bits)
+			if k.Sign() != 0 {
+				return k, nil
+			}
+		}
+	}
+}
+
+// SignedHash is a message digest with its signature.
+type SignedHash struct {
+	Hash []byte
+	Sig  *Signature
+}
+
+// BiasedNonceAttack recovers the private key behind signatures whose nonces
+// have their low bits zero. With k = 2**l * b, s = (e + d*r) / k gives
+// b = d*t - u mod n with t = r / (s * 2**l) and u = -e / (s * 2**l), and
+// every b is less than n / 2**l: a hidden number problem. The vector
+// (b_1, ..., b_m, d / 2**l, -n / 2**l) is a short vector of the lattice
+// spanned by n*e_i, (t_1, ..., t_m, 1 / 2**l, 0) and
+// (u_1, ..., u_m, 0, n / 2**l), which LLL finds once there are enough
+// signatures, a few more than N's bit length over l.
+// Link: https://cryptopals.com/sets/8/challenges/62
+func BiasedNonceAttack(params Parameters, pub Point, sigs []SignedHash, bits uint) (*PrivateKey, error) {
+	n := params.N
+	m := len(sigs)
+	scale := new(big.Int).Lsh(bigOne, bits)
+	ct := new(big.Rat).SetFrac(bigOne, scale)
+	cu := new(big.Rat).SetFrac(n, scale)
+
+	basis := make([]lattice.Vector, m+2)
+	for i := range basis {
+		basis[i] = make(lattice.Vector, m+2)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Rat)
+		}
+	}
+	for i, sh := range sigs {
+		basis[i][i].SetInt(n)
+		// 1 / (s * 2**l) mod n
+		inv := new(big.Int).Mul(sh.Sig.S, scale)
+		if inv.ModInverse(inv, n) == nil {
+			return nil, errors.New("signature out of range")
+		}
+		t := new(big.Int).Mul(sh.Sig.R, inv)
+		basis[m][i].SetInt(t.Mod(t, n))
+		u := hashToInt(sh.Hash, n)
+		u.Neg(u).Mul(u, inv)
+		basis[m+1][i].SetInt(u.Mod(u, n))
+	}
+	basis[m][m].Set(ct)
+	basis[m+1][m+1].Set(cu)
+
+	reduced, err := lattice.LLL(basis, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := params.Curve
+	for _, row := range reduced {
+		if new(big.Rat).Abs(row[m+1]).Cmp(cu) != 0 {
+			continue
+		}
+		// the row is +-(b, d / 2**l, -n / 2**l)
+		d := new(big.Rat).Mul(row[m], new(big.Rat).SetInt(scale))
+		if !d.IsInt() {
+			continue
+		}
+		if row[m+1].Sign() > 0 {
+			d.Neg(d)
+		}
+		x := new(big.Int).Mod(d.Num(), n)
+		if public := c.ScalarMult(params.G, x); public.Equal(pub) {
+			return &PrivateKey{params, x, public}, nil
+		}
+	}
+	return nil, errors.New("private key not found in the reduced basis")
+}
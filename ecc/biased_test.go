@@ -0,0 +1,41 @@
+package ecc
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBiasedNonce(t *testing.T) {
+	nonce := BiasedNonce(8)
+	for i := 0; i < 16; i++ {
+		k, err := nonce(DefaultParameters.N)
+		assert.NoError(t, err)
+		assert.True(t, k.Sign() > 0 && k.Cmp(DefaultParameters.N) < 0)
+		assert.Equal(t, 0, new(big.Int).And(k, big.NewInt(0xff)).Sign())
+	}
+}
+
+func TestBiasedNonceAttack(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	var sigs []SignedHash
+	for i := 0; i < 22; i++ {
+		hash := Hash([]byte(fmt.Sprintf("message %d", i)))
+		sig, err := Sign(priv, hash, BiasedNonce(8))
+		assert.NoError(t, err)
+		sigs = append(sigs, SignedHash{hash, sig})
+	}
+
+	found, err := BiasedNonceAttack(DefaultParameters, priv.Public, sigs, 8)
+	assert.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, 0, found.D.Cmp(priv.D))
+	}
+
+	// too few signatures leave the key out of reach
+	_, err = BiasedNonceAttack(DefaultParameters, priv.Public, sigs[:4], 8)
+	assert.Error(t, err)
+}
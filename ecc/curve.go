@@ -0,0 +1,173 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	bigOne   = big.NewInt(1)
+	bigTwo   = big.NewInt(2)
+	bigThree = big.NewInt(3)
+)
+
+// Curve is the short Weierstrass curve y**2 = x**3 + A*x + B over GF(P).
+// Nothing stops B from being anything, which is what invalid curve attacks
+// use: the addition law never looks at it.
+type Curve struct {
+	P, A, B *big.Int
+}
+
+// Point is a point of a curve; the zero Point is the point at infinity.
+type Point struct {
+	X, Y *big.Int
+}
+
+// Infinity is the identity of every curve.
+var Infinity = Point{}
+
+// IsInfinity tells whether p is the point at infinity.
+func (p Point) IsInfinity() bool {
+	return p.X == nil
+}
+
+// Equal tells whether p and q are the same point.
+func (p Point) Equal(q Point) bool {
+	if p.IsInfinity() || q.IsInfinity() {
+		return p.IsInfinity() == q.IsInfinity()
+	}
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+// NewPoint makes the point (x, y).
+func NewPoint(x, y int64) Point {
+	return Point{big.NewInt(x), big.NewInt(y)}
+}
+
+// WithB returns the curve with the same field and A but another B.
+func (c *Curve) WithB(b *big.Int) *Curve {
+	return &Curve{c.P, c.A, b}
+}
+
+// rhs computes x**3 + A*x + B mod P.
+func (c *Curve) rhs(x *big.Int) *big.Int {
+	y2 := new(big.Int).Mul(x, x)
+	y2.Add(y2, c.A).Mul(y2, x).Add(y2, c.B)
+	return y2.Mod(y2, c.P)
+}
+
+// IsOnCurve tells whether p satisfies the curve equation.
+func (c *Curve) IsOnCurve(p Point) bool {
+	if p.IsInfinity() {
+		return true
+	}
+	y2 := new(big.Int).Mul(p.Y, p.Y)
+	return y2.Mod(y2, c.P).Cmp(c.rhs(p.X)) == 0
+}
+
+// Neg returns -p.
+func (c *Curve) Neg(p Point) Point {
+	if p.IsInfinity() {
+		return p
+	}
+	y := new(big.Int).Neg(p.Y)
+	return Point{new(big.Int).Set(p.X), y.Mod(y, c.P)}
+}
+
+// Add returns p1 + p2 with the chord and tangent law.
+func (c *Curve) Add(p1, p2 Point) Point {
+	if p1.IsInfinity() {
+		return p2
+	}
+	if p2.IsInfinity() {
+		return p1
+	}
+	if p1.Equal(c.Neg(p2)) {
+		return Infinity
+	}
+
+	m := new(big.Int)
+	if p1.Equal(p2) {
+		// (3*x**2 + a) / 2*y
+		m.Mul(p1.X, p1.X).Mul(m, bigThree).Add(m, c.A)
+		den := new(big.Int).Mul(bigTwo, p1.Y)
+		m.Mul(m, den.ModInverse(den, c.P))
+	} else {
+		// (y2 - y1) / (x2 - x1)
+		m.Sub(p2.Y, p1.Y)
+		den := new(big.Int).Sub(p2.X, p1.X)
+		den.Mod(den, c.P)
+		m.Mul(m, den.ModInverse(den, c.P))
+	}
+	m.Mod(m, c.P)
+
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, p1.X).Sub(x3, p2.X).Mod(x3, c.P)
+	y3 := new(big.Int).Sub(p1.X, x3)
+	y3.Mul(y3, m).Sub(y3, p1.Y).Mod(y3, c.P)
+	return Point{x3, y3}
+}
+
+// Double returns 2p.
+func (c *Curve) Double(p Point) Point {
+	return c.Add(p, p)
+}
+
+// ScalarMult returns k*p by double and add. A negative k multiplies -p.
+func (c *Curve) ScalarMult(p Point, k *big.Int) Point {
+	if k.Sign() < 0 {
+		return c.ScalarMult(c.Neg(p), new(big.Int).Neg(k))
+	}
+	result := Infinity
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = c.Double(result)
+		if k.Bit(i) == 1 {
+			result = c.Add(result, p)
+		}
+	}
+	return result
+}
+
+// RandomPoint returns a random point of the curve other than infinity.
+func (c *Curve) RandomPoint() (Point, error) {
+	for {
+		x, err := rand.Int(rand.Reader, c.P)
+		if err != nil {
+			return Infinity, err
+		}
+		y := new(big.Int).ModSqrt(c.rhs(x), c.P)
+		if y == nil {
+			continue
+		}
+		return Point{x, y}, nil
+	}
+}
+
+// PointOfOrder returns a point of prime order r, for r dividing the order of
+// the curve. The whole power of r is divided out of the order first, since
+// the r-part of the group needn't be cyclic.
+func (c *Curve) PointOfOrder(r, order *big.Int) (Point, error) {
+	cofactor, mod := new(big.Int).DivMod(order, r, new(big.Int))
+	if mod.Sign() != 0 {
+		return Infinity, errors.New("r doesn't divide the curve order")
+	}
+	for new(big.Int).Mod(cofactor, r).Sign() == 0 {
+		cofactor.Div(cofactor, r)
+	}
+	for {
+		p, err := c.RandomPoint()
+		if err != nil {
+			return Infinity, err
+		}
+		p = c.ScalarMult(p, cofactor)
+		if p.IsInfinity() {
+			continue
+		}
+		// p has order r**i, go down to r
+		for q := c.ScalarMult(p, r); !q.IsInfinity(); q = c.ScalarMult(q, r) {
+			p = q
+		}
+		return p, nil
+	}
+}
@@ -0,0 +1,61 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurveGroupLaw(t *testing.T) {
+	c := DefaultParameters.Curve
+	g := DefaultParameters.G
+	assert.True(t, c.IsOnCurve(g))
+	assert.False(t, c.IsOnCurve(Point{g.X, new(big.Int).Add(g.Y, bigOne)}))
+	assert.True(t, c.IsOnCurve(Infinity))
+
+	p, err := c.RandomPoint()
+	assert.NoError(t, err)
+	q, err := c.RandomPoint()
+	assert.NoError(t, err)
+	assert.True(t, c.IsOnCurve(p))
+	assert.True(t, c.IsOnCurve(c.Add(p, q)))
+	assert.True(t, c.IsOnCurve(c.Double(p)))
+
+	// identity, inverse, commutativity, associativity
+	assert.Equal(t, p, c.Add(p, Infinity))
+	assert.Equal(t, p, c.Add(Infinity, p))
+	assert.True(t, c.Add(p, c.Neg(p)).IsInfinity())
+	assert.True(t, c.Add(p, q).Equal(c.Add(q, p)))
+	assert.True(t, c.Add(c.Add(p, q), g).Equal(c.Add(p, c.Add(q, g))))
+
+	// scalar multiplication agrees with repeated addition
+	sum := Infinity
+	for k := int64(0); k < 20; k++ {
+		assert.True(t, sum.Equal(c.ScalarMult(p, big.NewInt(k))), k)
+		sum = c.Add(sum, p)
+	}
+	assert.True(t, c.ScalarMult(p, big.NewInt(-3)).Equal(c.Neg(c.ScalarMult(p, big.NewInt(3)))))
+	assert.True(t, c.ScalarMult(g, DefaultParameters.N).IsInfinity())
+}
+
+func TestPointOfOrder(t *testing.T) {
+	invalid := InvalidCurves[0]
+	c := DefaultParameters.Curve.WithB(invalid.B)
+	r := big.NewInt(11)
+	p, err := c.PointOfOrder(r, invalid.Order)
+	assert.NoError(t, err)
+	assert.True(t, c.IsOnCurve(p))
+	assert.False(t, DefaultParameters.Curve.IsOnCurve(p))
+	assert.False(t, p.IsInfinity())
+	assert.True(t, c.ScalarMult(p, r).IsInfinity())
+
+	// the 2-torsion of this curve isn't cyclic
+	p, err = c.PointOfOrder(bigTwo, invalid.Order)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, p.Y.Sign())
+	assert.True(t, c.Double(p).IsInfinity())
+
+	_, err = c.PointOfOrder(big.NewInt(13), invalid.Order)
+	assert.Error(t, err)
+}
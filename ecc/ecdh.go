@@ -0,0 +1,95 @@
+package ecc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+)
+
+// Parameters are a curve and a base point G of prime order N.
+type Parameters struct {
+	Curve *Curve
+	G     Point
+	N     *big.Int
+}
+
+// PrivateKey is an ECDH key pair, Public = D*G.
+type PrivateKey struct {
+	Parameters
+	D      *big.Int
+	Public Point
+}
+
+func fromDecimal(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("Error: bad decimal constant " + s)
+	}
+	return x
+}
+
+// DefaultParameters are the curve and base point of the Cryptopals
+// challenges: y**2 = x**3 - 95051*x + 11279326, whose order is 8*N.
+var DefaultParameters = Parameters{
+	Curve: &Curve{
+		P: fromDecimal("233970423115425145524320034830162017933"),
+		A: big.NewInt(-95051),
+		B: big.NewInt(11279326),
+	},
+	G: Point{big.NewInt(182), fromDecimal("85518893674295321206118380980485522083")},
+	N: fromDecimal("29246302889428143187362802287225875743"),
+}
+
+// GenerateKey makes a key pair with D in [1, N).
+func GenerateKey(params Parameters) (*PrivateKey, error) {
+	d, err := RandomNonce(params.N)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{params, d, params.Curve.ScalarMult(params.G, d)}, nil
+}
+
+// SharedSecret returns D times the other party's public point. It doesn't
+// check that the point is on the curve.
+func (priv *PrivateKey) SharedSecret(pub Point) Point {
+	return priv.Curve.ScalarMult(pub, priv.D)
+}
+
+// MAC is the HMAC-SHA256 of msg keyed with both coordinates of a shared
+// secret.
+func MAC(secret Point, msg []byte) []byte {
+	var key []byte
+	if !secret.IsInfinity() {
+		key = append(secret.X.Bytes(), secret.Y.Bytes()...)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// Bob holds a long-term ECDH key and answers any public point with a message
+// MAC'd under the shared secret.
+type Bob struct {
+	priv *PrivateKey
+	msg  []byte
+}
+
+// NewBob makes a Bob with a random key.
+func NewBob(params Parameters) (*Bob, error) {
+	priv, err := GenerateKey(params)
+	if err != nil {
+		return nil, err
+	}
+	return &Bob{priv, []byte("crazy flamboyant for the rap enjoyment")}, nil
+}
+
+// PublicKey returns Bob's public point.
+func (b *Bob) PublicKey() Point {
+	return b.priv.Public
+}
+
+// Respond takes Alice's public point h and returns Bob's message and its MAC
+// under the shared secret D*h.
+func (b *Bob) Respond(h Point) ([]byte, []byte) {
+	return b.msg, MAC(b.priv.SharedSecret(h), b.msg)
+}
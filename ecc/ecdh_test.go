@@ -0,0 +1,22 @@
+package ecc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECDH(t *testing.T) {
+	alice, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	bob, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	assert.True(t, DefaultParameters.Curve.IsOnCurve(alice.Public))
+	assert.True(t, alice.SharedSecret(bob.Public).Equal(bob.SharedSecret(alice.Public)))
+
+	b, err := NewBob(DefaultParameters)
+	assert.NoError(t, err)
+	msg, mac := b.Respond(alice.Public)
+	assert.Equal(t, MAC(alice.SharedSecret(b.PublicKey()), msg), mac)
+	assert.NotEqual(t, MAC(Infinity, msg), mac)
+}
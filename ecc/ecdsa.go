@@ -0,0 +1,118 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// Signature is an ECDSA signature.
+type Signature struct {
+	R, S *big.Int
+}
+
+// NonceSource produces the per-signature secret k in [1, n).
+// It's injectable so that bad nonces can be simulated.
+type NonceSource func(n *big.Int) (*big.Int, error)
+
+// ErrZeroSignature is returned when a nonce gives r = 0 or s = 0.
+var ErrZeroSignature = errors.New("nonce produced a zero signature component")
+
+// Hash is the message digest used for ECDSA, SHA-256.
+func Hash(msg []byte) []byte {
+	digest := sha256.Sum256(msg)
+	return digest[:]
+}
+
+// RandomNonce draws k uniformly from [1, n).
+func RandomNonce(n *big.Int) (*big.Int, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(n, bigOne))
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, bigOne), nil
+}
+
+// hashToInt keeps the leftmost bits of the digest, as many as N has.
+func hashToInt(hash []byte, n *big.Int) *big.Int {
+	e := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - n.BitLen(); excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// Sign signs the digest of a message with a nonce from the source:
+// r = (k*G).x mod n and s = k**-1 (e + d*r) mod n.
+func Sign(priv *PrivateKey, hash []byte, nonce NonceSource) (*Signature, error) {
+	n := priv.N
+	k, err := nonce(n)
+	if err != nil {
+		return nil, err
+	}
+	kInverse := new(big.Int).ModInverse(k, n)
+	if kInverse == nil {
+		return nil, ErrZeroSignature
+	}
+	r := new(big.Int).Mod(priv.Curve.ScalarMult(priv.G, k).X, n)
+	s := new(big.Int).Mul(priv.D, r)
+	s.Add(s, hashToInt(hash, n)).Mul(s, kInverse).Mod(s, n)
+	if r.Sign() == 0 || s.Sign() == 0 {
+		return nil, ErrZeroSignature
+	}
+	return &Signature{r, s}, nil
+}
+
+// verifyPoint computes u1*G + u2*Q, whose x-coordinate is r for a good
+// signature, and returns it with u1 and u2.
+func verifyPoint(params Parameters, pub Point, hash []byte, sig *Signature) (Point, *big.Int, *big.Int, bool) {
+	n := params.N
+	if sig.R.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(n) >= 0 {
+		return Infinity, nil, nil, false
+	}
+	w := new(big.Int).ModInverse(sig.S, n)
+	u1 := hashToInt(hash, n)
+	u1.Mul(u1, w).Mod(u1, n)
+	u2 := new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, n)
+	c := params.Curve
+	return c.Add(c.ScalarMult(params.G, u1), c.ScalarMult(pub, u2)), u1, u2, true
+}
+
+// Verify checks the signature of a message digest under the public point.
+func Verify(params Parameters, pub Point, hash []byte, sig *Signature) bool {
+	p, _, _, ok := verifyPoint(params, pub, hash, sig)
+	if !ok || p.IsInfinity() {
+		return false
+	}
+	return new(big.Int).Mod(p.X, params.N).Cmp(sig.R) == 0
+}
+
+// ECDSAKeySelection makes another key under which sig is also a valid
+// signature of the digest: a signature only checks that
+// u1*G + u2*Q = R, and nothing ties G to the curve's usual generator. With
+// R = u1*G + u2*Q from the original, any d' gives G' = (u1 + u2*d')**-1 * R
+// and Q' = d'*G', for which u1*G' + u2*Q' = R again.
+// Link: https://cryptopals.com/sets/8/challenges/61
+func ECDSAKeySelection(params Parameters, pub Point, hash []byte, sig *Signature) (*PrivateKey, error) {
+	if !Verify(params, pub, hash, sig) {
+		return nil, errors.New("invalid signature")
+	}
+	r, u1, u2, _ := verifyPoint(params, pub, hash, sig)
+	n := params.N
+	for {
+		d, err := RandomNonce(n)
+		if err != nil {
+			return nil, err
+		}
+		t := new(big.Int).Mul(u2, d)
+		t.Add(t, u1).Mod(t, n)
+		if t.Sign() == 0 {
+			continue
+		}
+		g := params.Curve.ScalarMult(r, t.ModInverse(t, n))
+		forged := Parameters{params.Curve, g, n}
+		return &PrivateKey{forged, d, params.Curve.ScalarMult(g, d)}, nil
+	}
+}
@@ -0,0 +1,54 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECDSA(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	hash := Hash([]byte("hi mom"))
+	sig, err := Sign(priv, hash, RandomNonce)
+	assert.NoError(t, err)
+	assert.True(t, Verify(DefaultParameters, priv.Public, hash, sig))
+
+	assert.False(t, Verify(DefaultParameters, priv.Public, Hash([]byte("hi dad")), sig))
+	assert.False(t, Verify(DefaultParameters, DefaultParameters.G, hash, sig))
+	assert.False(t, Verify(DefaultParameters, priv.Public, hash, &Signature{sig.R, new(big.Int).Add(sig.S, bigOne)}))
+	assert.False(t, Verify(DefaultParameters, priv.Public, hash, &Signature{sig.R, DefaultParameters.N}))
+	assert.False(t, Verify(DefaultParameters, priv.Public, hash, &Signature{big.NewInt(0), sig.S}))
+
+	zero := func(n *big.Int) (*big.Int, error) { return n, nil }
+	_, err = Sign(priv, hash, zero)
+	assert.Equal(t, ErrZeroSignature, err)
+}
+
+func TestECDSAKeySelection(t *testing.T) {
+	priv, err := GenerateKey(DefaultParameters)
+	assert.NoError(t, err)
+	hash := Hash([]byte("I owe you nothing"))
+	sig, err := Sign(priv, hash, RandomNonce)
+	assert.NoError(t, err)
+
+	forged, err := ECDSAKeySelection(DefaultParameters, priv.Public, hash, sig)
+	assert.NoError(t, err)
+	assert.False(t, forged.Public.Equal(priv.Public))
+	assert.False(t, forged.G.Equal(DefaultParameters.G))
+	assert.True(t, Verify(forged.Parameters, forged.Public, hash, sig))
+
+	// the new key is a real key pair on the same curve
+	assert.True(t, DefaultParameters.Curve.IsOnCurve(forged.G))
+	other := Hash([]byte("something else"))
+	sig2, err := Sign(forged, other, RandomNonce)
+	assert.NoError(t, err)
+	assert.True(t, Verify(forged.Parameters, forged.Public, other, sig2))
+
+	// only a valid signature can be kept
+	_, err = ECDSAKeySelection(DefaultParameters, priv.Public, other, sig)
+	assert.Error(t, err)
+	_, err = ECDSAKeySelection(DefaultParameters, priv.Public, hash, &Signature{sig.R, big.NewInt(0)})
+	assert.Error(t, err)
+}
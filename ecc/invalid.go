@@ -0,0 +1,78 @@
+package ecc
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"gosano/crypto"
+)
+
+// Oracle answers a public point with a message and its MAC under the shared
+// secret, like Bob.Respond.
+type Oracle func(h Point) ([]byte, []byte)
+
+// InvalidCurve is a curve with the same field and A as the real one but
+// another B, along with its order.
+type InvalidCurve struct {
+	B, Order *big.Int
+}
+
+// InvalidCurves are curves next to DefaultParameters whose orders have many
+// small factors.
+var InvalidCurves = []InvalidCurve{
+	{big.NewInt(210), fromDecimal("233970423115425145550826547352470124412")},
+	{big.NewInt(504), fromDecimal("233970423115425145544350131142039591210")},
+	{big.NewInt(727), fromDecimal("233970423115425145545378039958152057148")},
+}
+
+// InvalidCurveAttack recovers the private key of an Oracle that doesn't check
+// points are on its curve. Since the addition law never uses B, a point of
+// small order r on another curve stays in that subgroup, and the shared
+// secret is one of its r multiples, which the MAC tells apart. The residues
+// of the key for the factors below bound of every curve order are combined
+// with the CRT until their product passes N.
+// Link: https://cryptopals.com/sets/8/challenges/59
+func InvalidCurveAttack(params Parameters, curves []InvalidCurve, bound int64, oracle Oracle) (*big.Int, error) {
+	var residues, moduli []*big.Int
+	used := make(map[string]bool)
+	product := big.NewInt(1)
+	for _, invalid := range curves {
+		curve := params.Curve.WithB(invalid.B)
+		for _, r := range crypto.SmallFactors(invalid.Order, bound) {
+			if used[r.String()] {
+				continue
+			}
+			h, err := curve.PointOfOrder(r, invalid.Order)
+			if err != nil {
+				return nil, err
+			}
+			msg, mac := oracle(h)
+			k, err := matchMAC(curve, h, r, msg, mac)
+			if err != nil {
+				return nil, err
+			}
+			used[r.String()] = true
+			residues = append(residues, k)
+			moduli = append(moduli, r)
+			if product.Mul(product, r).Cmp(params.N) > 0 {
+				x, _, err := crypto.CRT(residues, moduli)
+				return x, err
+			}
+		}
+	}
+	return nil, errors.New("the small factors don't cover the group order")
+}
+
+// matchMAC finds the k in [0, r) for which k*h is the key of mac.
+func matchMAC(curve *Curve, h Point, r *big.Int, msg, mac []byte) (*big.Int, error) {
+	secret := Infinity
+	for k := int64(0); big.NewInt(k).Cmp(r) < 0; k++ {
+		if hmac.Equal(mac, MAC(secret, msg)) {
+			return big.NewInt(k), nil
+		}
+		secret = curve.Add(secret, h)
+	}
+	return nil, fmt.Errorf("no multiple of the point of order %v matches", r)
+}
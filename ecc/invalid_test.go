@@ -0,0 +1,30 @@
+package ecc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidCurves(t *testing.T) {
+	for _, invalid := range InvalidCurves {
+		c := DefaultParameters.Curve.WithB(invalid.B)
+		p, err := c.RandomPoint()
+		assert.NoError(t, err)
+		assert.True(t, c.ScalarMult(p, invalid.Order).IsInfinity())
+	}
+}
+
+func TestInvalidCurveAttack(t *testing.T) {
+	bob, err := NewBob(DefaultParameters)
+	assert.NoError(t, err)
+	x, err := InvalidCurveAttack(DefaultParameters, InvalidCurves, 1<<16, bob.Respond)
+	assert.NoError(t, err)
+	assert.Equal(t, bob.priv.D, x)
+
+	// too few small factors to cover N
+	_, err = InvalidCurveAttack(DefaultParameters, InvalidCurves[:1], 1<<10, bob.Respond)
+	assert.Error(t, err)
+	_, err = InvalidCurveAttack(DefaultParameters, nil, 1<<16, bob.Respond)
+	assert.Error(t, err)
+}
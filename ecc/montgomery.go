@@ -0,0 +1,218 @@
+package ecc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// MontgomeryCurve is the curve B*v**2 = u**3 + A*u**2 + u over GF(P).
+type MontgomeryCurve struct {
+	P, A, B *big.Int
+}
+
+// MontgomeryParameters are a Montgomery curve and a base point G = (u, v) of
+// prime order N.
+type MontgomeryParameters struct {
+	Curve *MontgomeryCurve
+	G     Point
+	N     *big.Int
+}
+
+// DefaultMontgomeryParameters are DefaultParameters in Montgomery form,
+// v**2 = u**3 + 534*u**2 + u with u = x - 178.
+var DefaultMontgomeryParameters = MontgomeryParameters{
+	Curve: &MontgomeryCurve{
+		P: DefaultParameters.Curve.P,
+		A: big.NewInt(534),
+		B: big.NewInt(1),
+	},
+	G: Point{big.NewInt(4), fromDecimal("85518893674295321206118380980485522083")},
+	N: DefaultParameters.N,
+}
+
+// rhs computes (u**3 + A*u**2 + u) / B mod P, which is v**2 for points of
+// the curve and a non-square for points of its twist.
+func (m *MontgomeryCurve) rhs(u *big.Int) *big.Int {
+	v2 := new(big.Int).Add(u, m.A)
+	v2.Mul(v2, u).Add(v2, bigOne).Mul(v2, u)
+	v2.Mul(v2, new(big.Int).ModInverse(m.B, m.P))
+	return v2.Mod(v2, m.P)
+}
+
+// IsOnCurve tells whether p satisfies the curve equation.
+func (m *MontgomeryCurve) IsOnCurve(p Point) bool {
+	if p.IsInfinity() {
+		return true
+	}
+	v2 := new(big.Int).Mul(p.Y, p.Y)
+	return v2.Mod(v2, m.P).Cmp(m.rhs(p.X)) == 0
+}
+
+// OnTwist tells whether u is the u-coordinate of a point of the quadratic
+// twist rather than of the curve.
+func (m *MontgomeryCurve) OnTwist(u *big.Int) bool {
+	return big.Jacobi(m.rhs(u), m.P) == -1
+}
+
+// Lift returns one of the two points with u-coordinate u, or false when u
+// is on the twist.
+func (m *MontgomeryCurve) Lift(u *big.Int) (Point, bool) {
+	v := new(big.Int).ModSqrt(m.rhs(u), m.P)
+	if v == nil {
+		return Infinity, false
+	}
+	return Point{new(big.Int).Mod(u, m.P), v}, true
+}
+
+// Ladder computes the u-coordinate of k*(u, v) from u alone with the
+// Montgomery ladder. It never needs v or B, so it works the same on the
+// twist. The point at infinity comes out as 0.
+func (m *MontgomeryCurve) Ladder(u, k *big.Int) *big.Int {
+	p := m.P
+	u2, w2 := big.NewInt(1), big.NewInt(0)
+	u3, w3 := new(big.Int).Set(u), big.NewInt(1)
+	t1, t2 := new(big.Int), new(big.Int)
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		b := k.Bit(i)
+		if b == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+		// u3, w3 = (u2*u3 - w2*w3)**2, u*(u2*w3 - w2*u3)**2
+		t1.Mul(u2, u3).Sub(t1, t2.Mul(w2, w3))
+		newU3 := new(big.Int).Mul(t1, t1)
+		newU3.Mod(newU3, p)
+		t1.Mul(u2, w3).Sub(t1, t2.Mul(w2, u3))
+		newW3 := new(big.Int).Mul(t1, t1)
+		newW3.Mul(newW3, u).Mod(newW3, p)
+		// u2, w2 = (u2**2 - w2**2)**2, 4*u2*w2*(u2**2 + A*u2*w2 + w2**2)
+		t1.Mul(u2, u2)
+		t2.Mul(w2, w2)
+		newU2 := new(big.Int).Sub(t1, t2)
+		newU2.Mul(newU2, newU2).Mod(newU2, p)
+		newW2 := new(big.Int).Mul(m.A, u2)
+		newW2.Mul(newW2, w2).Add(newW2, t1).Add(newW2, t2)
+		newW2.Mul(newW2, u2).Mul(newW2, w2).Lsh(newW2, 2).Mod(newW2, p)
+		u2, w2, u3, w3 = newU2, newW2, newU3, newW3
+		if b == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+	}
+	if w2.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	w2.ModInverse(w2, p)
+	return u2.Mul(u2, w2).Mod(u2, p)
+}
+
+// Weierstrass returns the short Weierstrass curve the Montgomery curve maps
+// to with x = (u + A/3) / B, y = v / B:
+// a = (3 - A**2) / (3*B**2) and b = (2*A**3 - 9*A) / (27*B**3).
+func (m *MontgomeryCurve) Weierstrass() *Curve {
+	p := m.P
+	a2 := new(big.Int).Mul(m.A, m.A)
+	b2 := new(big.Int).Mul(m.B, m.B)
+
+	a := new(big.Int).Sub(bigThree, a2)
+	den := new(big.Int).Mul(bigThree, b2)
+	a.Mul(a, den.ModInverse(den, p)).Mod(a, p)
+
+	b := new(big.Int).Mul(bigTwo, a2)
+	b.Sub(b, big.NewInt(9)).Mul(b, m.A)
+	den.Mul(big.NewInt(27), b2).Mul(den, m.B)
+	b.Mul(b, den.ModInverse(den, p)).Mod(b, p)
+	return &Curve{P: p, A: a, B: b}
+}
+
+// ToWeierstrass maps a point of the curve to its Weierstrass form.
+func (m *MontgomeryCurve) ToWeierstrass(pt Point) Point {
+	if pt.IsInfinity() {
+		return pt
+	}
+	p := m.P
+	bInv := new(big.Int).ModInverse(m.B, p)
+	third := new(big.Int).ModInverse(bigThree, p)
+	x := new(big.Int).Mul(m.A, third)
+	x.Add(x, pt.X).Mul(x, bInv).Mod(x, p)
+	y := new(big.Int).Mul(pt.Y, bInv)
+	return Point{x, y.Mod(y, p)}
+}
+
+// FromWeierstrass maps a point of m.Weierstrass() back to the curve.
+func (m *MontgomeryCurve) FromWeierstrass(pt Point) Point {
+	if pt.IsInfinity() {
+		return pt
+	}
+	p := m.P
+	third := new(big.Int).ModInverse(bigThree, p)
+	u := new(big.Int).Mul(pt.X, m.B)
+	u.Sub(u, third.Mul(third, m.A)).Mod(u, p)
+	v := new(big.Int).Mul(pt.Y, m.B)
+	return Point{u, v.Mod(v, p)}
+}
+
+// MontgomeryFromWeierstrass finds the Montgomery form of a Weierstrass curve
+// given a root alpha of x**3 + a*x + b such that 3*alpha**2 + a is a square
+// s**-2: A = 3*alpha*s, B = s and u = s*(x - alpha).
+func MontgomeryFromWeierstrass(c *Curve, alpha *big.Int) (*MontgomeryCurve, error) {
+	p := c.P
+	if c.rhs(alpha).Sign() != 0 {
+		return nil, errors.New("alpha isn't a root of the curve polynomial")
+	}
+	t := new(big.Int).Mul(alpha, alpha)
+	t.Mul(t, bigThree).Add(t, c.A).Mod(t, p)
+	s := new(big.Int).ModSqrt(t, p)
+	if s == nil || s.Sign() == 0 {
+		return nil, errors.New("3*alpha**2 + a isn't a non-zero square")
+	}
+	s.ModInverse(s, p)
+	a := new(big.Int).Mul(bigThree, alpha)
+	a.Mul(a, s).Mod(a, p)
+	return &MontgomeryCurve{P: p, A: a, B: s}, nil
+}
+
+// XOracle answers a u-coordinate with a message and its MAC under the
+// x-only shared secret, like XBob.Respond.
+type XOracle func(u *big.Int) ([]byte, []byte)
+
+// XBob does ECDH on a Montgomery curve with u-coordinates only, and doesn't
+// check that the u he's sent is on the curve.
+type XBob struct {
+	params MontgomeryParameters
+	d      *big.Int
+	public *big.Int
+	msg    []byte
+}
+
+// NewXBob makes a Bob with a random key in [1, N).
+func NewXBob(params MontgomeryParameters) (*XBob, error) {
+	d, err := rand.Int(rand.Reader, new(big.Int).Sub(params.N, bigOne))
+	if err != nil {
+		return nil, err
+	}
+	d.Add(d, bigOne)
+	public := params.Curve.Ladder(params.G.X, d)
+	return &XBob{params, d, public, []byte("crazy flamboyant for the rap enjoyment")}, nil
+}
+
+// PublicKey returns the u-coordinate of Bob's public point.
+func (b *XBob) PublicKey() *big.Int {
+	return b.public
+}
+
+// Respond takes Alice's u-coordinate and returns Bob's message and its MAC
+// under the shared secret ladder(u, d).
+func (b *XBob) Respond(u *big.Int) ([]byte, []byte) {
+	return b.msg, XMAC(b.params.Curve.Ladder(u, b.d), b.msg)
+}
+
+// XMAC is the HMAC-SHA256 of msg keyed with an x-only shared secret.
+func XMAC(secret *big.Int, msg []byte) []byte {
+	mac := hmac.New(sha256.New, secret.Bytes())
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,67 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMontgomeryWeierstrass(t *testing.T) {
+	m := DefaultMontgomeryParameters.Curve
+	c := DefaultParameters.Curve
+	w := m.Weierstrass()
+	assert.Equal(t, new(big.Int).Mod(c.A, c.P), w.A)
+	assert.Equal(t, c.B, w.B)
+
+	g := DefaultMontgomeryParameters.G
+	assert.True(t, m.IsOnCurve(g))
+	assert.True(t, m.ToWeierstrass(g).Equal(DefaultParameters.G))
+	assert.True(t, m.FromWeierstrass(DefaultParameters.G).Equal(g))
+
+	p, err := c.RandomPoint()
+	assert.NoError(t, err)
+	assert.True(t, m.IsOnCurve(m.FromWeierstrass(p)))
+	assert.True(t, m.ToWeierstrass(m.FromWeierstrass(p)).Equal(p))
+	assert.True(t, m.ToWeierstrass(Infinity).IsInfinity())
+
+	back, err := MontgomeryFromWeierstrass(c, big.NewInt(178))
+	assert.NoError(t, err)
+	assert.Equal(t, m.A, back.A)
+	assert.Equal(t, m.B, back.B)
+	_, err = MontgomeryFromWeierstrass(c, big.NewInt(179))
+	assert.Error(t, err)
+}
+
+func TestLadder(t *testing.T) {
+	params := DefaultMontgomeryParameters
+	m := params.Curve
+	c := DefaultParameters.Curve
+	for _, k := range []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(12345), fromDecimal("9876543210987654321")} {
+		want := m.FromWeierstrass(c.ScalarMult(DefaultParameters.G, k))
+		assert.Equal(t, want.X, m.Ladder(params.G.X, k), k.String())
+	}
+	assert.Equal(t, 0, m.Ladder(params.G.X, params.N).Sign())
+
+	// u = 76600469441198017145391791613091732004 is on the twist
+	u := fromDecimal("76600469441198017145391791613091732004")
+	assert.True(t, m.OnTwist(u))
+	_, ok := m.Lift(u)
+	assert.False(t, ok)
+	assert.False(t, m.OnTwist(params.G.X))
+	lifted, ok := m.Lift(params.G.X)
+	assert.True(t, ok)
+	assert.True(t, m.IsOnCurve(lifted))
+}
+
+func TestXBob(t *testing.T) {
+	params := DefaultMontgomeryParameters
+	bob, err := NewXBob(params)
+	assert.NoError(t, err)
+	alice, err := NewXBob(params)
+	assert.NoError(t, err)
+	secret := params.Curve.Ladder(bob.PublicKey(), alice.d)
+	assert.Equal(t, secret, params.Curve.Ladder(alice.PublicKey(), bob.d))
+	msg, mac := bob.Respond(alice.PublicKey())
+	assert.Equal(t, XMAC(secret, msg), mac)
+}
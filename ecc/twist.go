@@ -0,0 +1,155 @@
+package ecc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"gosano/crypto"
+)
+
+// curveGroup is the group generated by g on c, for crypto.KangarooIn.
+type curveGroup struct {
+	c *Curve
+	g Point
+}
+
+func (cg curveGroup) Exp(x *big.Int) interface{} {
+	return cg.c.ScalarMult(cg.g, x)
+}
+
+func (cg curveGroup) Mul(u, v interface{}) interface{} {
+	return cg.c.Add(u.(Point), v.(Point))
+}
+
+func (cg curveGroup) Equal(u, v interface{}) bool {
+	return u.(Point).Equal(v.(Point))
+}
+
+// Label picks the walk by the x-coordinate of the points.
+func (cg curveGroup) Label(u interface{}) *big.Int {
+	p := u.(Point)
+	if p.IsInfinity() {
+		return new(big.Int)
+	}
+	return p.X
+}
+
+// Kangaroo finds x in [a, b] with x*g = y on the curve with Pollard's lambda
+// method, walking as crypto.Kangaroo does with the jumps picked by the
+// x-coordinate of the points.
+// Link: https://cryptopals.com/sets/8/challenges/60
+func Kangaroo(c *Curve, g, y Point, a, b *big.Int, params *crypto.KangarooParams) (*big.Int, error) {
+	return crypto.KangarooIn(curveGroup{c, g}, y, a, b, params)
+}
+
+// twistPointOfOrder returns the u-coordinate of a point of the twist of the
+// given order, a product of the distinct primes that appear only once in the
+// twist order.
+func (m *MontgomeryCurve) twistPointOfOrder(twistOrder, order *big.Int, primes []*big.Int) (*big.Int, error) {
+	cofactor := new(big.Int).Div(twistOrder, order)
+	for {
+		u, err := rand.Int(rand.Reader, m.P)
+		if err != nil {
+			return nil, err
+		}
+		if !m.OnTwist(u) {
+			continue
+		}
+		h := m.Ladder(u, cofactor)
+		ok := h.Sign() != 0
+		for _, r := range primes {
+			ok = ok && m.Ladder(h, new(big.Int).Div(order, r)).Sign() != 0
+		}
+		if ok {
+			return h, nil
+		}
+	}
+}
+
+// TwistAttack recovers the key of an XOracle from u-coordinates on the
+// quadratic twist of its curve, which the x-only ladder can't tell from the
+// curve. Since u alone doesn't give the sign of a point, the key is only
+// known up to sign in every subgroup; to keep the signs consistent each
+// query uses a point whose order is the product of all the factors so far,
+// and the MAC picks which of the candidates extends the known residue. The
+// factors of the twist order below bound get the key up to sign modulo R,
+// then Kangaroo on the Weierstrass form of the curve finds the rest, about
+// sqrt(N/R) steps for each sign of the residue and of the public point.
+// Link: https://cryptopals.com/sets/8/challenges/60
+func TwistAttack(params MontgomeryParameters, twistOrder *big.Int, bound int64, public *big.Int, oracle XOracle) (*big.Int, error) {
+	m := params.Curve
+	var primes []*big.Int
+	n, modulus := big.NewInt(0), big.NewInt(1)
+	for _, r := range crypto.SmallFactors(twistOrder, bound) {
+		// no points of order 2 with x-only arithmetic, and only cyclic
+		// subgroups
+		square := new(big.Int).Mul(r, r)
+		if r.Cmp(bigTwo) == 0 || new(big.Int).Mod(twistOrder, square).Sign() == 0 {
+			continue
+		}
+		primes = append(primes, r)
+		order := new(big.Int).Mul(modulus, r)
+		h, err := m.twistPointOfOrder(twistOrder, order, primes)
+		if err != nil {
+			return nil, err
+		}
+		msg, mac := oracle(h)
+		found := false
+		for k := int64(0); big.NewInt(k).Cmp(r) < 0 && !found; k++ {
+			c, _, err := crypto.CRT([]*big.Int{n, big.NewInt(k)}, []*big.Int{modulus, r})
+			if err != nil {
+				return nil, err
+			}
+			if hmac.Equal(mac, XMAC(m.Ladder(h, c), msg)) {
+				n, found = c, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no key mod %v matches", order)
+		}
+		modulus = order
+	}
+
+	// the key is n or -n mod R
+	check := func(d *big.Int) bool {
+		return m.Ladder(params.G.X, d).Cmp(public) == 0
+	}
+	candidates := []*big.Int{n, new(big.Int).Sub(modulus, n)}
+	if modulus.Cmp(params.N) > 0 {
+		for _, d := range candidates {
+			if check(d) {
+				return d, nil
+			}
+		}
+		return nil, errors.New("no key matches the public key")
+	}
+
+	curve := m.Weierstrass()
+	g := m.ToWeierstrass(params.G)
+	pub, ok := m.Lift(public)
+	if !ok {
+		return nil, errors.New("public key isn't on the curve")
+	}
+	q := m.ToWeierstrass(pub)
+	// d = n + k*R, so (d - n)*g = k*(R*g)
+	gPrime := curve.ScalarMult(g, modulus)
+	maxK := new(big.Int).Div(params.N, modulus)
+	for _, d0 := range candidates {
+		for _, target := range []Point{q, curve.Neg(q)} {
+			y := curve.Add(target, curve.Neg(curve.ScalarMult(g, d0)))
+			k, err := Kangaroo(curve, gPrime, y, big.NewInt(0), maxK, nil)
+			if err != nil {
+				continue
+			}
+			d := new(big.Int).Mul(k, modulus)
+			d.Add(d, d0)
+			if check(d) {
+				return d, nil
+			}
+		}
+	}
+	return nil, errors.New("no key matches the public key")
+}
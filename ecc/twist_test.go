@@ -0,0 +1,68 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gosano/crypto"
+)
+
+// a 48-bit Montgomery curve of order 4*N whose twist has the order
+// 4 * 13 * 271 * 467 * 1663 * c, leaving about 2**14 for the kangaroo
+var (
+	testTwistParameters = MontgomeryParameters{
+		Curve: &MontgomeryCurve{P: big.NewInt(220513532931199), A: big.NewInt(903), B: big.NewInt(1)},
+		G:     NewPoint(103318411109118, 51547939451385),
+		N:     big.NewInt(55128381473933),
+	}
+	testTwistOrder = big.NewInt(220513539966668)
+)
+
+func TestTwistParameters(t *testing.T) {
+	params := testTwistParameters
+	m := params.Curve
+	assert.True(t, m.IsOnCurve(params.G))
+	assert.Equal(t, 0, m.Ladder(params.G.X, params.N).Sign())
+	// the orders of the curve and its twist add up to 2p + 2
+	order := new(big.Int).Mul(params.N, big.NewInt(4))
+	sum := new(big.Int).Add(order, testTwistOrder)
+	assert.Equal(t, new(big.Int).Lsh(new(big.Int).Add(m.P, bigOne), 1), sum)
+	for i := 0; i < 3; i++ {
+		h, err := m.twistPointOfOrder(testTwistOrder, big.NewInt(13*271), []*big.Int{big.NewInt(13), big.NewInt(271)})
+		assert.NoError(t, err)
+		assert.True(t, m.OnTwist(h))
+		assert.Equal(t, 0, m.Ladder(h, big.NewInt(13*271)).Sign())
+	}
+}
+
+func TestECKangaroo(t *testing.T) {
+	params := testTwistParameters
+	c := params.Curve.Weierstrass()
+	g := params.Curve.ToWeierstrass(params.G)
+	x := big.NewInt(987654)
+	got, err := Kangaroo(c, g, c.ScalarMult(g, x), big.NewInt(0), big.NewInt(1<<20), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, x, got)
+
+	// a log past the interval isn't returned
+	walk := &crypto.KangarooParams{
+		Jumps:     []*big.Int{big.NewInt(1), big.NewInt(3), big.NewInt(7), big.NewInt(11)},
+		Index:     func(y *big.Int) int { return int(y.Bit(3)<<1 | y.Bit(7)) },
+		TameJumps: 100,
+		WildTries: 50,
+	}
+	_, err = Kangaroo(c, g, c.ScalarMult(g, big.NewInt(7000)), big.NewInt(5000), big.NewInt(6000), walk)
+	assert.Equal(t, crypto.ErrNoDiscreteLog, err)
+}
+
+func TestTwistAttack(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		bob, err := NewXBob(testTwistParameters)
+		assert.NoError(t, err)
+		d, err := TwistAttack(testTwistParameters, testTwistOrder, 1<<12, bob.PublicKey(), bob.Respond)
+		assert.NoError(t, err)
+		assert.Equal(t, bob.d, d)
+	}
+}
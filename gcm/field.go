@@ -0,0 +1,124 @@
+package gcm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// Element is an element of GF(2**128) = GF(2)[x] / (x**128 + x**7 + x**2 +
+// x + 1) in GCM's bit order: the first bit of a block, the top bit of its
+// first byte, is the coefficient of x**0. hi holds the first 8 bytes and lo
+// the last 8, both big endian.
+type Element struct {
+	hi, lo uint64
+}
+
+// ElementFromBytes reads a block as an element. Shorter inputs are padded
+// with zeros, as GHASH does with the last block.
+func ElementFromBytes(b []byte) Element {
+	var block [16]byte
+	copy(block[:], b)
+	return Element{binary.BigEndian.Uint64(block[:8]), binary.BigEndian.Uint64(block[8:])}
+}
+
+// Bytes returns the block of e.
+func (e Element) Bytes() []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[:8], e.hi)
+	binary.BigEndian.PutUint64(b[8:], e.lo)
+	return b
+}
+
+// String formats e as the hex of its block.
+func (e Element) String() string {
+	return fmt.Sprintf("%016x%016x", e.hi, e.lo)
+}
+
+// Zero and One are the identities of addition and multiplication.
+var (
+	Zero = Element{}
+	One  = Element{1 << 63, 0}
+)
+
+// X is the element x.
+var X = Element{1 << 62, 0}
+
+// IsZero tells whether e is 0.
+func (e Element) IsZero() bool {
+	return e == Zero
+}
+
+// Coeff returns the coefficient of x**i in e.
+func (e Element) Coeff(i int) uint {
+	if i < 64 {
+		return uint(e.hi>>(63-uint(i))) & 1
+	}
+	return uint(e.lo>>(127-uint(i))) & 1
+}
+
+// Add returns e + f, which is also e - f.
+func (e Element) Add(f Element) Element {
+	return Element{e.hi ^ f.hi, e.lo ^ f.lo}
+}
+
+// gcmR is x**128 reduced, x**7 + x**2 + x + 1, in GCM's bit order.
+const gcmR = 0xe1 << 56
+
+// Mul returns e * f with the shift-and-add of the GCM specification: f is
+// multiplied by x, which is a right shift in this bit order, once per
+// coefficient of e.
+func (e Element) Mul(f Element) Element {
+	var z Element
+	v := f
+	for i := 0; i < 128; i++ {
+		if e.Coeff(i) == 1 {
+			z.hi ^= v.hi
+			z.lo ^= v.lo
+		}
+		carry := v.lo & 1
+		v.lo = v.lo>>1 | v.hi<<63
+		v.hi >>= 1
+		if carry == 1 {
+			v.hi ^= gcmR
+		}
+	}
+	return z
+}
+
+// Square returns e**2.
+func (e Element) Square() Element {
+	return e.Mul(e)
+}
+
+// Exp returns e**k.
+func (e Element) Exp(k uint64) Element {
+	result := One
+	for i := 63 - bits.LeadingZeros64(k); i >= 0; i-- {
+		result = result.Square()
+		if k>>uint(i)&1 == 1 {
+			result = result.Mul(e)
+		}
+	}
+	return result
+}
+
+// Inverse returns 1/e as e**(2**128 - 2). The inverse of 0 comes out as 0.
+func (e Element) Inverse() Element {
+	// e**(2**127 - 1) by adding one bit at a time to the exponent, then
+	// squared
+	result := e
+	for i := 1; i < 127; i++ {
+		result = result.Square().Mul(e)
+	}
+	return result.Square()
+}
+
+// Sqrt returns the square root of e, e**(2**127). Squaring is a bijection of
+// GF(2**128), so every element has exactly one.
+func (e Element) Sqrt() Element {
+	for i := 0; i < 127; i++ {
+		e = e.Square()
+	}
+	return e
+}
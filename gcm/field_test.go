@@ -0,0 +1,50 @@
+package gcm
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomElement(t *testing.T) Element {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	assert.NoError(t, err)
+	return ElementFromBytes(b)
+}
+
+func TestElementBytes(t *testing.T) {
+	b := []byte("YELLOW SUBMARINE")
+	assert.Equal(t, b, ElementFromBytes(b).Bytes())
+	assert.Equal(t, append([]byte("abc"), make([]byte, 13)...), ElementFromBytes([]byte("abc")).Bytes())
+	assert.Equal(t, uint(1), One.Coeff(0))
+	assert.Equal(t, uint(1), X.Coeff(1))
+	assert.Equal(t, uint(0), X.Coeff(0))
+	assert.Equal(t, "80000000000000000000000000000000", One.String())
+}
+
+func TestElementMul(t *testing.T) {
+	// x**127 * x = x**128 = x**7 + x**2 + x + 1
+	x127 := X.Exp(127)
+	assert.Equal(t, uint(1), x127.Coeff(127))
+	assert.Equal(t, Element{0xe1 << 56, 0}, x127.Mul(X))
+
+	a, b, c := randomElement(t), randomElement(t), randomElement(t)
+	assert.Equal(t, a, a.Mul(One))
+	assert.Equal(t, Zero, a.Mul(Zero))
+	assert.Equal(t, a.Mul(b), b.Mul(a))
+	assert.Equal(t, a.Mul(b).Mul(c), a.Mul(b.Mul(c)))
+	assert.Equal(t, a.Mul(b.Add(c)), a.Mul(b).Add(a.Mul(c)))
+	assert.Equal(t, a.Mul(a).Mul(a), a.Exp(3))
+}
+
+func TestElementInverse(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		a := randomElement(t)
+		assert.Equal(t, One, a.Mul(a.Inverse()))
+		assert.Equal(t, a, a.Sqrt().Square())
+		assert.Equal(t, a, a.Square().Sqrt())
+	}
+	assert.Equal(t, Zero, Zero.Inverse())
+}
@@ -0,0 +1,83 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"errors"
+)
+
+// Message is a GCM ciphertext with its nonce, associated data and tag.
+type Message struct {
+	Nonce, AD, Ciphertext, Tag []byte
+}
+
+// ghashPoly returns the polynomial GHASH evaluates: the last block is the
+// coefficient of y, the one before of y**2, and so on, with no constant
+// term.
+func ghashPoly(ad, ciphertext []byte) Poly {
+	var blocks []Element
+	for _, data := range [][]byte{ad, ciphertext} {
+		for i := 0; i < len(data); i += aes.BlockSize {
+			end := i + aes.BlockSize
+			if end > len(data) {
+				end = len(data)
+			}
+			blocks = append(blocks, ElementFromBytes(data[i:end]))
+		}
+	}
+	blocks = append(blocks, lengthBlock(ad, ciphertext))
+	p := make(Poly, len(blocks)+1)
+	for i, b := range blocks {
+		p[len(blocks)-i] = b
+	}
+	return p.normalize()
+}
+
+// tagPoly returns GHASH(y) + tag, whose value at H is the tag mask
+// E(nonce || 1).
+func tagPoly(m Message) Poly {
+	return ghashPoly(m.AD, m.Ciphertext).Add(NewPoly(ElementFromBytes(m.Tag)))
+}
+
+// NonceReuseAttack finds the candidates for the authentication key H from
+// messages sealed under the same key and nonce, which share the tag mask:
+// the difference of two of the polynomials GHASH(y) + tag vanishes at H.
+// The roots of the first two messages' difference are the candidates, and
+// the other messages weed them out.
+// Link: https://cryptopals.com/sets/8/challenges/63
+func NonceReuseAttack(messages []Message) ([]Element, error) {
+	if len(messages) < 2 {
+		return nil, errors.New("need two messages under the same nonce")
+	}
+	for _, m := range messages {
+		if len(m.Tag) != aes.BlockSize {
+			return nil, errors.New("need full 16-byte tags")
+		}
+	}
+	first := tagPoly(messages[0])
+	candidates, err := Roots(first.Add(tagPoly(messages[1])))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range messages[2:] {
+		diff := first.Add(tagPoly(m))
+		var kept []Element
+		for _, h := range candidates {
+			if diff.Eval(h).IsZero() {
+				kept = append(kept, h)
+			}
+		}
+		candidates = kept
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("no candidate for H")
+	}
+	return candidates, nil
+}
+
+// ForgeTag computes the tag of another ciphertext under the nonce of known
+// given the authentication key h: the tag mask is the known tag minus its
+// GHASH.
+func ForgeTag(h Element, known Message, ad, ciphertext []byte) []byte {
+	mask := ElementFromBytes(known.Tag).Add(GHASH(h, known.AD, known.Ciphertext))
+	return GHASH(h, ad, ciphertext).Add(mask).Bytes()
+}
@@ -0,0 +1,59 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGHASHPoly(t *testing.T) {
+	h := randomElement(t)
+	ad, ciphertext := []byte("some header"), []byte("and a ciphertext of a few blocks")
+	assert.Equal(t, GHASH(h, ad, ciphertext), ghashPoly(ad, ciphertext).Eval(h))
+	assert.Equal(t, GHASH(h, nil, nil), ghashPoly(nil, nil).Eval(h))
+}
+
+func TestNonceReuseAttack(t *testing.T) {
+	key := make([]byte, 16)
+	nonce := make([]byte, NonceSize)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	_, err = rand.Read(nonce)
+	assert.NoError(t, err)
+	g, err := NewGCM(key)
+	assert.NoError(t, err)
+
+	var messages []Message
+	for _, pt := range []string{
+		"Transfer 100 dollars to Alice",
+		"Transfer 200 dollars to Bob, please",
+		"Nothing to see here",
+	} {
+		ad := []byte("bank")
+		sealed, err := g.Seal(nonce, []byte(pt), ad)
+		assert.NoError(t, err)
+		n := len(sealed) - aes.BlockSize
+		messages = append(messages, Message{nonce, ad, sealed[:n], sealed[n:]})
+	}
+
+	candidates, err := NonceReuseAttack(messages[:2])
+	assert.NoError(t, err)
+	assert.Contains(t, candidates, g.h)
+
+	candidates, err = NonceReuseAttack(messages)
+	assert.NoError(t, err)
+	assert.Equal(t, []Element{g.h}, candidates)
+
+	// with H, any ciphertext under that nonce can be authenticated
+	forged := append([]byte{}, messages[0].Ciphertext...)
+	forged[9] ^= '1' ^ '9'
+	tag := ForgeTag(candidates[0], messages[0], []byte("evil"), forged)
+	plaintext, err := g.Open(nonce, append(forged, tag...), []byte("evil"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Transfer 900 dollars to Alice", string(plaintext))
+
+	_, err = NonceReuseAttack(messages[:1])
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// NonceSize is the size of GCM nonces; other sizes aren't supported.
+const NonceSize = 12
+
+// ErrOpen is returned when a ciphertext doesn't authenticate.
+var ErrOpen = errors.New("message authentication failed")
+
+// GCM is AES in Galois/Counter Mode: CTR encryption with the counter
+// starting at nonce || 2, and a GHASH of the associated data and ciphertext
+// under H = E(0) masked with E(nonce || 1) as the tag.
+type GCM struct {
+	block   cipher.Block
+	h       Element
+	tagSize int
+}
+
+// NewGCM makes an AES-GCM with 16-byte tags. The key is 16, 24 or 32 bytes.
+func NewGCM(key []byte) (*GCM, error) {
+	return NewGCMWithTagSize(key, 16)
+}
+
+// NewGCMWithTagSize makes an AES-GCM whose tags are truncated to tagSize
+// bytes. Unlike crypto/cipher it takes anything from 1 to 16, short tags
+// being the point of the truncated MAC attack.
+func NewGCMWithTagSize(key []byte, tagSize int) (*GCM, error) {
+	if tagSize < 1 || tagSize > 16 {
+		return nil, fmt.Errorf("invalid GCM tag size %d", tagSize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	h := make([]byte, aes.BlockSize)
+	block.Encrypt(h, h)
+	return &GCM{block, ElementFromBytes(h), tagSize}, nil
+}
+
+// TagSize returns the size of the tags.
+func (g *GCM) TagSize() int {
+	return g.tagSize
+}
+
+// counter returns the block nonce || i.
+func counter(nonce []byte, i uint32) []byte {
+	block := make([]byte, aes.BlockSize)
+	copy(block, nonce)
+	binary.BigEndian.PutUint32(block[NonceSize:], i)
+	return block
+}
+
+// ctr XORs src with the keystream that starts at counter 2.
+func (g *GCM) ctr(nonce, src []byte) []byte {
+	dst := make([]byte, len(src))
+	stream := make([]byte, aes.BlockSize)
+	for i := 0; i < len(src); i += aes.BlockSize {
+		g.block.Encrypt(stream, counter(nonce, uint32(i/aes.BlockSize+2)))
+		for j := i; j < len(src) && j < i+aes.BlockSize; j++ {
+			dst[j] = src[j] ^ stream[j-i]
+		}
+	}
+	return dst
+}
+
+// GHASH is the polynomial MAC of GCM: the associated data and ciphertext,
+// both zero-padded to whole blocks, then a block with their lengths in bits,
+// are the coefficients of a polynomial evaluated at h. The last block is
+// the coefficient of h, so the constant term is 0.
+func GHASH(h Element, ad, ciphertext []byte) Element {
+	var y Element
+	for _, data := range [][]byte{ad, ciphertext} {
+		for i := 0; i < len(data); i += aes.BlockSize {
+			end := i + aes.BlockSize
+			if end > len(data) {
+				end = len(data)
+			}
+			y = y.Add(ElementFromBytes(data[i:end])).Mul(h)
+		}
+	}
+	return y.Add(lengthBlock(ad, ciphertext)).Mul(h)
+}
+
+// lengthBlock is the last GHASH block, len(ad) || len(ciphertext) in bits.
+func lengthBlock(ad, ciphertext []byte) Element {
+	return Element{uint64(len(ad)) * 8, uint64(len(ciphertext)) * 8}
+}
+
+// tag computes the full 16-byte tag of a ciphertext.
+func (g *GCM) tag(nonce, ad, ciphertext []byte) []byte {
+	mask := make([]byte, aes.BlockSize)
+	g.block.Encrypt(mask, counter(nonce, 1))
+	return GHASH(g.h, ad, ciphertext).Add(ElementFromBytes(mask)).Bytes()
+}
+
+// Seal encrypts and authenticates the plaintext and authenticates the
+// associated data, and returns the ciphertext followed by the tag.
+func (g *GCM) Seal(nonce, plaintext, ad []byte) ([]byte, error) {
+	if len(nonce) != NonceSize {
+		return nil, fmt.Errorf("invalid GCM nonce size %d", len(nonce))
+	}
+	ciphertext := g.ctr(nonce, plaintext)
+	return append(ciphertext, g.tag(nonce, ad, ciphertext)[:g.tagSize]...), nil
+}
+
+// Open checks the tag at the end of sealed and decrypts the ciphertext.
+func (g *GCM) Open(nonce, sealed, ad []byte) ([]byte, error) {
+	if len(nonce) != NonceSize {
+		return nil, fmt.Errorf("invalid GCM nonce size %d", len(nonce))
+	}
+	if len(sealed) < g.tagSize {
+		return nil, ErrOpen
+	}
+	ciphertext, tag := sealed[:len(sealed)-g.tagSize], sealed[len(sealed)-g.tagSize:]
+	if subtle.ConstantTimeCompare(g.tag(nonce, ad, ciphertext)[:g.tagSize], tag) != 1 {
+		return nil, ErrOpen
+	}
+	return g.ctr(nonce, ciphertext), nil
+}
@@ -0,0 +1,73 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCM(t *testing.T) {
+	key := make([]byte, 16)
+	nonce := make([]byte, NonceSize)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	std, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	g, err := NewGCM(key)
+	assert.NoError(t, err)
+
+	for _, size := range []int{0, 1, 15, 16, 17, 100} {
+		plaintext := make([]byte, size)
+		ad := make([]byte, size/2)
+		_, err = rand.Read(plaintext)
+		assert.NoError(t, err)
+		_, err = rand.Read(nonce)
+		assert.NoError(t, err)
+
+		sealed, err := g.Seal(nonce, plaintext, ad)
+		assert.NoError(t, err)
+		assert.Equal(t, std.Seal(nil, nonce, plaintext, ad), sealed, "size %d", size)
+
+		opened, err := g.Open(nonce, sealed, ad)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, append([]byte{}, opened...))
+
+		sealed[0] ^= 1
+		_, err = g.Open(nonce, sealed, ad)
+		assert.Equal(t, ErrOpen, err)
+	}
+
+	_, err = g.Seal(nonce[:8], nil, nil)
+	assert.Error(t, err)
+	_, err = NewGCMWithTagSize(key, 17)
+	assert.Error(t, err)
+}
+
+func TestGCMWithTagSize(t *testing.T) {
+	key := make([]byte, 32)
+	nonce := make([]byte, NonceSize)
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	std, err := cipher.NewGCMWithTagSize(block, 12)
+	assert.NoError(t, err)
+	g, err := NewGCMWithTagSize(key, 12)
+	assert.NoError(t, err)
+	sealed, err := g.Seal(nonce, []byte("attack at dawn"), []byte("header"))
+	assert.NoError(t, err)
+	assert.Equal(t, std.Seal(nil, nonce, []byte("attack at dawn"), []byte("header")), sealed)
+
+	g, err = NewGCMWithTagSize(key, 2)
+	assert.NoError(t, err)
+	short, err := g.Seal(nonce, []byte("attack at dawn"), []byte("header"))
+	assert.NoError(t, err)
+	assert.Equal(t, sealed[:len(sealed)-10], short)
+	_, err = g.Open(nonce, short, []byte("header"))
+	assert.NoError(t, err)
+	_, err = g.Open(nonce, short[:1], nil)
+	assert.Equal(t, ErrOpen, err)
+}
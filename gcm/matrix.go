@@ -0,0 +1,40 @@
+package gcm
+
+import (
+	"math/bits"
+
+	"gosano/gf2"
+)
+
+// elementVector returns the coefficients of e as a vector, x**i at i.
+func elementVector(e Element) gf2.Vector {
+	return gf2.Vector{bits.Reverse64(e.hi), bits.Reverse64(e.lo)}
+}
+
+// vectorElement is the inverse of elementVector.
+func vectorElement(v gf2.Vector) Element {
+	return Element{bits.Reverse64(v[0]), bits.Reverse64(v[1])}
+}
+
+// mulMatrix returns the 128x128 matrix of multiplication by c: column j is
+// c * x**j.
+func mulMatrix(c Element) *gf2.Matrix {
+	columns := make([]gf2.Vector, 128)
+	for j := range columns {
+		columns[j] = elementVector(c)
+		c = c.Mul(X)
+	}
+	return gf2.FromColumns(128, columns)
+}
+
+// squareMatrix returns the 128x128 matrix of squaring, which is linear in
+// characteristic 2: column j is x**(2*j).
+func squareMatrix() *gf2.Matrix {
+	columns := make([]gf2.Vector, 128)
+	x := One
+	for j := range columns {
+		columns[j] = elementVector(x.Square())
+		x = x.Mul(X)
+	}
+	return gf2.FromColumns(128, columns)
+}
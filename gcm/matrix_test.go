@@ -0,0 +1,19 @@
+package gcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElementMatrices(t *testing.T) {
+	a, b := randomElement(t), randomElement(t)
+	assert.Equal(t, a.Mul(b), vectorElement(mulMatrix(a).Apply(elementVector(b))))
+	assert.Equal(t, a.Square(), vectorElement(squareMatrix().Apply(elementVector(a))))
+	m := mulMatrix(a).Mul(squareMatrix())
+	assert.Equal(t, a.Mul(b.Square()), vectorElement(m.Apply(elementVector(b))))
+
+	// multiplication by a non-zero element is invertible
+	assert.Empty(t, mulMatrix(a).Kernel())
+	assert.Equal(t, 128, squareMatrix().Rank())
+}
@@ -0,0 +1,373 @@
+package gcm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// Poly is a polynomial over GF(2**128), its coefficients from the constant
+// term up. Polynomials returned by this package have no leading zeros; the
+// zero polynomial is empty.
+type Poly []Element
+
+// NewPoly makes the polynomial with the coefficients, constant term first.
+func NewPoly(coeffs ...Element) Poly {
+	return Poly(append([]Element{}, coeffs...)).normalize()
+}
+
+func (p Poly) normalize() Poly {
+	for len(p) > 0 && p[len(p)-1].IsZero() {
+		p = p[:len(p)-1]
+	}
+	return p
+}
+
+// Degree returns the degree of p, -1 for the zero polynomial.
+func (p Poly) Degree() int {
+	return len(p.normalize()) - 1
+}
+
+// IsZero tells whether p is 0.
+func (p Poly) IsZero() bool {
+	return p.Degree() < 0
+}
+
+// Lead returns the leading coefficient of p.
+func (p Poly) Lead() Element {
+	p = p.normalize()
+	if len(p) == 0 {
+		return Zero
+	}
+	return p[len(p)-1]
+}
+
+// Equal tells whether p and q are the same polynomial.
+func (p Poly) Equal(q Poly) bool {
+	p, q = p.normalize(), q.normalize()
+	if len(p) != len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats p as a sum of monomials, highest degree first.
+func (p Poly) String() string {
+	p = p.normalize()
+	if len(p) == 0 {
+		return "0"
+	}
+	var terms []string
+	for i := len(p) - 1; i >= 0; i-- {
+		if !p[i].IsZero() {
+			terms = append(terms, p[i].String()+"*y^"+strconv.Itoa(i))
+		}
+	}
+	return strings.Join(terms, " + ")
+}
+
+// Add returns p + q, which is also p - q.
+func (p Poly) Add(q Poly) Poly {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+	sum := append(Poly{}, p...)
+	for i, c := range q {
+		sum[i] = sum[i].Add(c)
+	}
+	return sum.normalize()
+}
+
+// Mul returns p * q.
+func (p Poly) Mul(q Poly) Poly {
+	p, q = p.normalize(), q.normalize()
+	if len(p) == 0 || len(q) == 0 {
+		return Poly{}
+	}
+	product := make(Poly, len(p)+len(q)-1)
+	for i, a := range p {
+		if a.IsZero() {
+			continue
+		}
+		for j, b := range q {
+			product[i+j] = product[i+j].Add(a.Mul(b))
+		}
+	}
+	return product.normalize()
+}
+
+// Square returns p**2. The cross terms cancel in characteristic 2, so only
+// the coefficients are squared.
+func (p Poly) Square() Poly {
+	p = p.normalize()
+	if len(p) == 0 {
+		return Poly{}
+	}
+	square := make(Poly, 2*len(p)-1)
+	for i, c := range p {
+		square[2*i] = c.Square()
+	}
+	return square
+}
+
+// Scale returns c * p.
+func (p Poly) Scale(c Element) Poly {
+	scaled := make(Poly, len(p))
+	for i, a := range p {
+		scaled[i] = a.Mul(c)
+	}
+	return scaled.normalize()
+}
+
+// DivMod returns the quotient and remainder of p divided by q. It panics if
+// q is zero.
+func (p Poly) DivMod(q Poly) (Poly, Poly) {
+	q = q.normalize()
+	if len(q) == 0 {
+		panic("Error: polynomial division by zero")
+	}
+	rem := append(Poly{}, p.normalize()...)
+	if len(rem) < len(q) {
+		return Poly{}, rem
+	}
+	quot := make(Poly, len(rem)-len(q)+1)
+	inv := q.Lead().Inverse()
+	for d := len(rem) - 1; d >= len(q)-1; d-- {
+		c := rem[d].Mul(inv)
+		if c.IsZero() {
+			continue
+		}
+		shift := d - len(q) + 1
+		quot[shift] = c
+		for i, b := range q {
+			rem[shift+i] = rem[shift+i].Add(c.Mul(b))
+		}
+	}
+	return quot.normalize(), rem.normalize()
+}
+
+// Mod returns p mod q.
+func (p Poly) Mod(q Poly) Poly {
+	_, rem := p.DivMod(q)
+	return rem
+}
+
+// Monic returns p divided by its leading coefficient.
+func (p Poly) Monic() Poly {
+	if p.IsZero() {
+		return Poly{}
+	}
+	return p.Scale(p.Lead().Inverse())
+}
+
+// Derivative returns the formal derivative of p. i*c is c for odd i and 0
+// for even i in characteristic 2.
+func (p Poly) Derivative() Poly {
+	if len(p) < 2 {
+		return Poly{}
+	}
+	derivative := make(Poly, len(p)-1)
+	for i := 1; i < len(p); i += 2 {
+		derivative[i-1] = p[i]
+	}
+	return derivative.normalize()
+}
+
+// Eval returns p(y) with Horner's rule.
+func (p Poly) Eval(y Element) Element {
+	var result Element
+	for i := len(p) - 1; i >= 0; i-- {
+		result = result.Mul(y).Add(p[i])
+	}
+	return result
+}
+
+// sqrt returns the polynomial whose square is p, for p with only even
+// powers, which is what a zero derivative means in characteristic 2.
+func (p Poly) sqrt() Poly {
+	root := make(Poly, (len(p)+1)/2)
+	for i := range root {
+		root[i] = p[2*i].Sqrt()
+	}
+	return root.normalize()
+}
+
+// Gcd returns the monic greatest common divisor of p and q.
+func Gcd(p, q Poly) Poly {
+	p, q = p.normalize(), q.normalize()
+	for !q.IsZero() {
+		p, q = q, p.Mod(q)
+	}
+	return p.Monic()
+}
+
+// Factor is an irreducible factor and its multiplicity.
+type Factor struct {
+	Poly         Poly
+	Multiplicity int
+}
+
+// FactorPoly factors p into monic irreducible polynomials with
+// Cantor-Zassenhaus: a square-free factorization, then a distinct-degree
+// factorization of each square-free part, then a randomized equal-degree
+// split. The leading coefficient of p is dropped.
+func FactorPoly(p Poly) ([]Factor, error) {
+	var factors []Factor
+	for _, sf := range squareFree(p.Monic()) {
+		for _, dd := range distinctDegree(sf.Poly) {
+			irreducible, err := equalDegree(dd.poly, dd.degree)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range irreducible {
+				factors = append(factors, Factor{f, sf.Multiplicity})
+			}
+		}
+	}
+	return factors, nil
+}
+
+// Roots returns the distinct roots of p in GF(2**128). It only needs the
+// linear factors: gcd(p, y**(2**128) - y) is the product of the distinct
+// ones, which equalDegree splits.
+func Roots(p Poly) ([]Element, error) {
+	p = p.Monic()
+	if p.Degree() < 1 {
+		return nil, nil
+	}
+	linear := Gcd(p, frobenius(NewPoly(Zero, One), p).Add(NewPoly(Zero, One)))
+	if linear.Degree() < 1 {
+		return nil, nil
+	}
+	factors, err := equalDegree(linear, 1)
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]Element, len(factors))
+	for i, f := range factors {
+		// monic y + c has the root c
+		roots[i] = f[0]
+	}
+	return roots, nil
+}
+
+// frobenius returns h**(2**128) mod f.
+func frobenius(h, f Poly) Poly {
+	h = h.Mod(f)
+	for i := 0; i < 128; i++ {
+		h = h.Square().Mod(f)
+	}
+	return h
+}
+
+// squareFree splits monic f into square-free factors with their
+// multiplicities. gcd(f, f') holds the repeated factors, except those whose
+// multiplicity is even, which have a zero derivative: once the rest is
+// peeled off they're a square, whose root is factored again.
+func squareFree(f Poly) []Factor {
+	var factors []Factor
+	if f.Degree() < 1 {
+		return factors
+	}
+	c := Gcd(f, f.Derivative())
+	w, _ := f.DivMod(c)
+	for i := 1; w.Degree() > 0; i++ {
+		y := Gcd(w, c)
+		factor, _ := w.DivMod(y)
+		if factor.Degree() > 0 {
+			factors = append(factors, Factor{factor, i})
+		}
+		w = y
+		c, _ = c.DivMod(y)
+	}
+	if c.Degree() > 0 {
+		for _, factor := range squareFree(c.sqrt()) {
+			factors = append(factors, Factor{factor.Poly, 2 * factor.Multiplicity})
+		}
+	}
+	return factors
+}
+
+// sameDegree is a product of irreducible factors of the given degree.
+type sameDegree struct {
+	poly   Poly
+	degree int
+}
+
+// distinctDegree splits square-free monic f into products of irreducible
+// factors of the same degree. The factors of degree i are
+// gcd(f, y**(q**i) - y) once those of lower degree are gone, with
+// q = 2**128.
+func distinctDegree(f Poly) []sameDegree {
+	var factors []sameDegree
+	y := NewPoly(Zero, One)
+	h := y
+	for i := 1; f.Degree() >= 2*i; i++ {
+		h = frobenius(h, f)
+		g := Gcd(f, h.Add(y))
+		if g.Degree() > 0 {
+			factors = append(factors, sameDegree{g, i})
+			f, _ = f.DivMod(g)
+			h = h.Mod(f)
+		}
+	}
+	if f.Degree() > 0 {
+		factors = append(factors, sameDegree{f, f.Degree()})
+	}
+	return factors
+}
+
+// equalDegree splits monic f, a product of distinct irreducible polynomials
+// of degree d, into them. In characteristic 2 the trace
+// a + a**2 + ... + a**(2**(128*d - 1)) of a random a is 0 or 1 modulo each
+// factor, about half the time each, so its gcd with f is usually a proper
+// factor.
+func equalDegree(f Poly, d int) ([]Poly, error) {
+	if f.Degree() <= d {
+		return []Poly{f}, nil
+	}
+	for {
+		a, err := randomPoly(f.Degree())
+		if err != nil {
+			return nil, err
+		}
+		trace := a
+		for i := 1; i < 128*d; i++ {
+			a = a.Square().Mod(f)
+			trace = trace.Add(a)
+		}
+		g := Gcd(f, trace)
+		if g.Degree() <= 0 || g.Degree() == f.Degree() {
+			continue
+		}
+		other, _ := f.DivMod(g)
+		left, err := equalDegree(g, d)
+		if err != nil {
+			return nil, err
+		}
+		right, err := equalDegree(other, d)
+		if err != nil {
+			return nil, err
+		}
+		return append(left, right...), nil
+	}
+}
+
+// randomPoly returns a random polynomial of degree less than n.
+func randomPoly(n int) (Poly, error) {
+	buf := make([]byte, 16*n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	p := make(Poly, n)
+	for i := range p {
+		p[i] = Element{binary.BigEndian.Uint64(buf[16*i:]), binary.BigEndian.Uint64(buf[16*i+8:])}
+	}
+	return p.normalize(), nil
+}
@@ -0,0 +1,87 @@
+package gcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomPolyOfDegree(t *testing.T, d int) Poly {
+	p := make(Poly, d+1)
+	for i := range p {
+		p[i] = randomElement(t)
+	}
+	p[d] = One
+	return p
+}
+
+// linear returns y - c.
+func linear(c Element) Poly {
+	return NewPoly(c, One)
+}
+
+func TestPolyArithmetic(t *testing.T) {
+	p, q := randomPolyOfDegree(t, 5), randomPolyOfDegree(t, 3)
+	assert.Equal(t, 8, p.Mul(q).Degree())
+	assert.True(t, p.Mul(p).Equal(p.Square()))
+	assert.True(t, p.Add(p).IsZero())
+
+	quot, rem := p.Mul(q).Add(linear(One)).DivMod(q)
+	assert.True(t, quot.Equal(p))
+	assert.True(t, rem.Equal(linear(One).Mod(q)))
+
+	// the roots of (y - a)(y - b)
+	a, b := randomElement(t), randomElement(t)
+	r := linear(a).Mul(linear(b))
+	assert.Equal(t, Zero, r.Eval(a))
+	assert.Equal(t, Zero, r.Eval(b))
+	assert.True(t, r.Derivative().Equal(NewPoly(a.Add(b))))
+
+	assert.True(t, Gcd(r.Mul(p), r.Mul(q).Scale(a)).Equal(r))
+	assert.Equal(t, One, p.Scale(a).Monic().Lead())
+	assert.Equal(t, -1, Poly{Zero, Zero}.Degree())
+	assert.Panics(t, func() { p.DivMod(Poly{}) })
+}
+
+func TestRoots(t *testing.T) {
+	a, b, c := randomElement(t), randomElement(t), randomElement(t)
+	// a repeated root counts once
+	p := linear(a).Square().Mul(linear(b)).Mul(linear(c)).Scale(a)
+	roots, err := Roots(p)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []Element{a, b, c}, roots)
+
+	roots, err = Roots(NewPoly(a))
+	assert.NoError(t, err)
+	assert.Empty(t, roots)
+}
+
+func TestFactorPoly(t *testing.T) {
+	a, b := randomElement(t), randomElement(t)
+	quadratic := randomPolyOfDegree(t, 2)
+	for {
+		if roots, err := Roots(quadratic); assert.NoError(t, err) && len(roots) == 0 {
+			break
+		}
+		quadratic = randomPolyOfDegree(t, 2)
+	}
+	p := linear(a).Mul(linear(a)).Mul(linear(a)).Mul(linear(b)).Mul(quadratic).Mul(quadratic)
+	factors, err := FactorPoly(p)
+	assert.NoError(t, err)
+
+	expected := map[string]int{
+		linear(a).String(): 3,
+		linear(b).String(): 1,
+		quadratic.String(): 2,
+	}
+	got := map[string]int{}
+	product := NewPoly(One)
+	for _, f := range factors {
+		got[f.Poly.String()] += f.Multiplicity
+		for i := 0; i < f.Multiplicity; i++ {
+			product = product.Mul(f.Poly)
+		}
+	}
+	assert.Equal(t, expected, got)
+	assert.True(t, product.Equal(p))
+}
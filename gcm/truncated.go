@@ -0,0 +1,163 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"gosano/gf2"
+)
+
+// ForgeryOracle tells whether a ciphertext authenticates when sent with the
+// nonce, associated data and tag of the message under attack.
+type ForgeryOracle func(ciphertext []byte) bool
+
+// TruncatedMACAttack recovers the authentication key H of a GCM with short
+// tags from one message of whole blocks and forgery attempts.
+//
+// Only the blocks that are the coefficients of y**(2**i) are changed. As
+// squaring is linear over GF(2), the tag then changes by A_d * H for a
+// 128x128 bit matrix A_d linear in the bits of the changes d. Picking d in
+// the kernel of the map from d to the first rows of A_d, those tag bits
+// don't change and a forgery only has to guess the others. A forgery that
+// goes through says that the remaining rows of A_d annihilate H too, which
+// are equations on H. Writing H = X * h' with X a basis of their solutions,
+// rows only need to vanish on X, so more of them can be zeroed on the next
+// round, until one solution is left.
+//
+// It gives up after maxTries forgeries and returns the number of tries.
+// Link: https://cryptopals.com/sets/8/challenges/64
+func TruncatedMACAttack(msg Message, oracle ForgeryOracle, maxTries int) (Element, int, error) {
+	tagBits := 8 * len(msg.Tag)
+	if len(msg.Ciphertext)%aes.BlockSize != 0 {
+		return Zero, 0, errors.New("the ciphertext must be whole blocks")
+	}
+	blocks := len(msg.Ciphertext) / aes.BlockSize
+	// the last block is the coefficient of y**2, and the first one of
+	// y**(blocks+1)
+	n := 0
+	for 1<<uint(n+1) <= blocks+1 {
+		n++
+	}
+	if n == 0 || tagBits < 2 {
+		return Zero, 0, errors.New("message or tag too short")
+	}
+	effects := truncatedEffects(n, tagBits)
+	cols := len(effects)
+
+	equations := gf2.NewMatrix(0, 128)
+	basis := equations.Kernel()
+	tries := 0
+	for len(basis) > 1 {
+		k := len(basis)
+		zeroed := (cols - 1) / k
+		if zeroed > tagBits-1 {
+			zeroed = tagBits - 1
+		}
+
+		// the rows of A_d * X to be zeroed as a function of d
+		t := gf2.NewMatrix(zeroed*k, cols)
+		for col, effect := range effects {
+			for r := 0; r < zeroed; r++ {
+				for c, x := range basis {
+					if effect.Rows[r].Dot(x) == 1 {
+						t.Rows[r*k+c].Flip(col)
+					}
+				}
+			}
+		}
+		free := t.Kernel()
+		if len(free) == 0 {
+			return Zero, tries, errors.New("no change keeps the tag")
+		}
+
+		for {
+			if tries >= maxTries {
+				return Zero, tries, fmt.Errorf("no forgery in %d tries", maxTries)
+			}
+			d, err := randomCombination(free, cols)
+			if err != nil {
+				return Zero, tries, err
+			}
+			tries++
+			if !oracle(truncatedForgery(msg.Ciphertext, d, n)) {
+				continue
+			}
+			a := gf2.NewMatrix(tagBits, 128)
+			for col := range effects {
+				if d.Get(col) == 1 {
+					for r := range a.Rows {
+						a.Rows[r].Xor(effects[col].Rows[r])
+					}
+				}
+			}
+			for _, row := range a.Rows[zeroed:] {
+				if !row.IsZero() {
+					equations.AppendRow(row)
+				}
+			}
+			break
+		}
+		basis = equations.Kernel()
+		if len(basis) == 0 {
+			return Zero, tries, errors.New("the equations on H have no solution")
+		}
+	}
+	return vectorElement(basis[0]), tries, nil
+}
+
+// truncatedEffects returns, for every bit b of the change to the
+// coefficient of y**(2**i), the first rows of M(x**b) * S**i, where M(c) is
+// the multiplication by c and S the squaring: its effect on the tag as a
+// function of H. Bit b of coefficient i is at column 128*(i-1) + b.
+func truncatedEffects(n, tagBits int) []*gf2.Matrix {
+	square := squareMatrix()
+	power := square
+	var effects []*gf2.Matrix
+	for i := 1; i <= n; i++ {
+		c := One
+		for b := 0; b < 128; b++ {
+			m := mulMatrix(c)
+			m.Rows = m.Rows[:tagBits]
+			effects = append(effects, m.Mul(power))
+			c = c.Mul(X)
+		}
+		power = power.Mul(square)
+	}
+	return effects
+}
+
+// randomCombination returns a random non-zero sum of the vectors.
+func randomCombination(vectors []gf2.Vector, size int) (gf2.Vector, error) {
+	coins := make([]byte, len(vectors))
+	for {
+		if _, err := rand.Read(coins); err != nil {
+			return nil, err
+		}
+		d := gf2.NewVector(size)
+		for i, v := range vectors {
+			if coins[i]&1 == 1 {
+				d.Xor(v)
+			}
+		}
+		if !d.IsZero() {
+			return d, nil
+		}
+	}
+}
+
+// truncatedForgery adds the changes d to the coefficients of y**(2**i) of
+// the ciphertext.
+func truncatedForgery(ciphertext []byte, d gf2.Vector, n int) []byte {
+	forged := append([]byte{}, ciphertext...)
+	blocks := len(ciphertext) / aes.BlockSize
+	for i := 1; i <= n; i++ {
+		change := vectorElement(d[2*(i-1) : 2*i]).Bytes()
+		start := (blocks + 1 - 1<<uint(i)) * aes.BlockSize
+		for j, b := range change {
+			forged[start+j] ^= b
+		}
+	}
+	return forged
+}
@@ -0,0 +1,41 @@
+package gcm
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncatedMACAttack(t *testing.T) {
+	// challenge 64 uses 32-bit tags and 2**17 blocks; 16-bit tags and 2**9
+	// blocks keep the same structure with far fewer forgeries
+	key := make([]byte, 16)
+	nonce := make([]byte, NonceSize)
+	plaintext := make([]byte, 16<<9)
+	for _, b := range [][]byte{key, nonce, plaintext} {
+		_, err := rand.Read(b)
+		assert.NoError(t, err)
+	}
+	g, err := NewGCMWithTagSize(key, 2)
+	assert.NoError(t, err)
+	ad := []byte("header")
+	sealed, err := g.Seal(nonce, plaintext, ad)
+	assert.NoError(t, err)
+	n := len(plaintext)
+	msg := Message{nonce, ad, sealed[:n], sealed[n:]}
+
+	oracle := func(ciphertext []byte) bool {
+		_, err := g.Open(nonce, append(ciphertext, msg.Tag...), ad)
+		return err == nil
+	}
+	h, tries, err := TruncatedMACAttack(msg, oracle, 1<<16)
+	assert.NoError(t, err)
+	assert.Equal(t, g.h, h)
+	t.Logf("%d forgeries", tries)
+
+	_, _, err = TruncatedMACAttack(msg, oracle, 10)
+	assert.Error(t, err)
+	_, _, err = TruncatedMACAttack(Message{nonce, ad, msg.Ciphertext[:15], msg.Tag}, oracle, 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,191 @@
+package gf2
+
+import (
+	"fmt"
+)
+
+// Matrix is a matrix over GF(2) stored as its rows.
+type Matrix struct {
+	Rows []Vector
+	Cols int
+}
+
+// NewMatrix makes the zero matrix of the given size.
+func NewMatrix(rows, cols int) *Matrix {
+	m := &Matrix{make([]Vector, rows), cols}
+	for i := range m.Rows {
+		m.Rows[i] = NewVector(cols)
+	}
+	return m
+}
+
+// Identity makes the n x n identity matrix.
+func Identity(n int) *Matrix {
+	m := NewMatrix(n, n)
+	for i := range m.Rows {
+		m.Rows[i].Flip(i)
+	}
+	return m
+}
+
+// FromColumns makes the matrix with the given columns of length rows.
+func FromColumns(rows int, columns []Vector) *Matrix {
+	m := NewMatrix(rows, len(columns))
+	for j, column := range columns {
+		for i := 0; i < rows; i++ {
+			if column.Get(i) == 1 {
+				m.Rows[i].Flip(j)
+			}
+		}
+	}
+	return m
+}
+
+// Get returns the entry at row i and column j.
+func (m *Matrix) Get(i, j int) uint {
+	return m.Rows[i].Get(j)
+}
+
+// Set sets the entry at row i and column j to the low bit of b.
+func (m *Matrix) Set(i, j int, b uint) {
+	m.Rows[i].Set(j, b)
+}
+
+// AppendRow adds a row at the bottom of m.
+func (m *Matrix) AppendRow(row Vector) {
+	m.Rows = append(m.Rows, row)
+}
+
+// Clone returns a copy of m.
+func (m *Matrix) Clone() *Matrix {
+	c := &Matrix{make([]Vector, len(m.Rows)), m.Cols}
+	for i, row := range m.Rows {
+		c.Rows[i] = row.Clone()
+	}
+	return c
+}
+
+// Equal tells whether m and n are the same matrix.
+func (m *Matrix) Equal(n *Matrix) bool {
+	if len(m.Rows) != len(n.Rows) || m.Cols != n.Cols {
+		return false
+	}
+	for i := range m.Rows {
+		if !m.Rows[i].Equal(n.Rows[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Transpose returns the transpose of m.
+func (m *Matrix) Transpose() *Matrix {
+	return FromColumns(m.Cols, m.Rows)
+}
+
+// Mul returns m * n, adding up the rows of n picked by each row of m.
+func (m *Matrix) Mul(n *Matrix) *Matrix {
+	if m.Cols != len(n.Rows) {
+		panic(fmt.Sprintf("Error: multiplying %dx%d and %dx%d matrices", len(m.Rows), m.Cols, len(n.Rows), n.Cols))
+	}
+	product := NewMatrix(len(m.Rows), n.Cols)
+	for i, row := range m.Rows {
+		for k := 0; k < m.Cols; k++ {
+			if row.Get(k) == 1 {
+				product.Rows[i].Xor(n.Rows[k])
+			}
+		}
+	}
+	return product
+}
+
+// Apply returns m * v.
+func (m *Matrix) Apply(v Vector) Vector {
+	w := NewVector(len(m.Rows))
+	for i, row := range m.Rows {
+		if row.Dot(v) == 1 {
+			w.Flip(i)
+		}
+	}
+	return w
+}
+
+// Eliminate returns the reduced row echelon form of m with Gauss-Jordan
+// elimination, and the pivot column of each of its non-zero rows, which
+// come first. m isn't modified.
+func (m *Matrix) Eliminate() (*Matrix, []int) {
+	r := m.Clone()
+	var pivots []int
+	for c := 0; c < m.Cols && len(pivots) < len(r.Rows); c++ {
+		top := len(pivots)
+		p := top
+		for p < len(r.Rows) && r.Rows[p].Get(c) == 0 {
+			p++
+		}
+		if p == len(r.Rows) {
+			continue
+		}
+		r.Rows[top], r.Rows[p] = r.Rows[p], r.Rows[top]
+		for i := range r.Rows {
+			if i != top && r.Rows[i].Get(c) == 1 {
+				r.Rows[i].Xor(r.Rows[top])
+			}
+		}
+		pivots = append(pivots, c)
+	}
+	return r, pivots
+}
+
+// Rank returns the rank of m.
+func (m *Matrix) Rank() int {
+	_, pivots := m.Eliminate()
+	return len(pivots)
+}
+
+// Kernel returns a basis of the vectors v with m * v = 0. Every column
+// without a pivot gives the vector with a 1 there and the values the pivot
+// rows force on the pivot columns.
+func (m *Matrix) Kernel() []Vector {
+	r, pivots := m.Eliminate()
+	isPivot := make([]bool, m.Cols)
+	for _, c := range pivots {
+		isPivot[c] = true
+	}
+	var basis []Vector
+	for free := 0; free < m.Cols; free++ {
+		if isPivot[free] {
+			continue
+		}
+		v := NewVector(m.Cols)
+		v.Flip(free)
+		for i, c := range pivots {
+			if r.Rows[i].Get(free) == 1 {
+				v.Flip(c)
+			}
+		}
+		basis = append(basis, v)
+	}
+	return basis
+}
+
+// Solve returns an x with m * x = b, the one with zeros on the free
+// columns, or false when there's none. Adding any vector of the kernel
+// gives the other solutions.
+func (m *Matrix) Solve(b Vector) (Vector, bool) {
+	// eliminate [m | b], with b as the last column
+	augmented := NewMatrix(len(m.Rows), m.Cols+1)
+	for i, row := range m.Rows {
+		copy(augmented.Rows[i], row)
+		augmented.Rows[i].Set(m.Cols, b.Get(i))
+	}
+	r, pivots := augmented.Eliminate()
+	x := NewVector(m.Cols)
+	for i, c := range pivots {
+		if c == m.Cols {
+			// 0 = 1
+			return nil, false
+		}
+		x.Set(c, r.Rows[i].Get(m.Cols))
+	}
+	return x, true
+}
@@ -0,0 +1,82 @@
+package gf2
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomMatrix(random *rand.Rand, rows, cols int) *Matrix {
+	m := NewMatrix(rows, cols)
+	for i := range m.Rows {
+		for j := 0; j < cols; j++ {
+			m.Set(i, j, uint(random.Intn(2)))
+		}
+	}
+	return m
+}
+
+func TestMatrixMul(t *testing.T) {
+	random := rand.New(rand.NewSource(48))
+	a, b, c := randomMatrix(random, 5, 70), randomMatrix(random, 70, 130), randomMatrix(random, 130, 3)
+	assert.True(t, a.Mul(b).Mul(c).Equal(a.Mul(b.Mul(c))))
+	assert.True(t, a.Mul(Identity(70)).Equal(a))
+	assert.True(t, a.Mul(b).Transpose().Equal(b.Transpose().Mul(a.Transpose())))
+	assert.True(t, a.Transpose().Transpose().Equal(a))
+
+	v := randomMatrix(random, 1, 130).Rows[0]
+	x := NewMatrix(130, 1)
+	for i := 0; i < 130; i++ {
+		x.Set(i, 0, v.Get(i))
+	}
+	assert.Equal(t, b.Mul(x).Transpose().Rows[0], b.Apply(v))
+
+	assert.Panics(t, func() { a.Mul(c) })
+}
+
+func TestEliminate(t *testing.T) {
+	m := &Matrix{[]Vector{{0b110}, {0b011}, {0b101}}, 3}
+	r, pivots := m.Eliminate()
+	assert.Equal(t, []int{0, 1}, pivots)
+	assert.Equal(t, []Vector{{0b101}, {0b110}, {0}}, r.Rows)
+	assert.Equal(t, 2, m.Rank())
+	// m is left alone
+	assert.Equal(t, Vector{0b110}, m.Rows[0])
+
+	assert.Equal(t, 128, Identity(128).Rank())
+	assert.Empty(t, Identity(128).Kernel())
+}
+
+func TestKernel(t *testing.T) {
+	random := rand.New(rand.NewSource(8))
+	for _, size := range [][2]int{{10, 30}, {128, 200}, {200, 128}, {300, 300}} {
+		m := randomMatrix(random, size[0], size[1])
+		// make some rows dependent
+		m.Rows[1] = m.Rows[0].Clone()
+		kernel := m.Kernel()
+		assert.Equal(t, size[1], m.Rank()+len(kernel))
+		for _, v := range kernel {
+			assert.True(t, m.Apply(v).IsZero())
+		}
+		basis := &Matrix{kernel, size[1]}
+		assert.Equal(t, len(kernel), basis.Rank())
+	}
+}
+
+func TestSolve(t *testing.T) {
+	random := rand.New(rand.NewSource(64))
+	m := randomMatrix(random, 150, 128)
+	x := randomMatrix(random, 1, 128).Rows[0]
+	b := m.Apply(x)
+	solution, ok := m.Solve(b)
+	assert.True(t, ok)
+	assert.True(t, m.Apply(solution).Equal(b))
+
+	// more equations than unknowns make most right-hand sides impossible
+	b.Flip(0)
+	if m.Rank() == 128 {
+		_, ok = m.Solve(b)
+		assert.False(t, ok)
+	}
+}
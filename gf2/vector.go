@@ -0,0 +1,98 @@
+package gf2
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// Vector is a vector over GF(2) packed 64 coordinates per word, coordinate
+// i being bit i%64 of word i/64. It doesn't know its own length: bits past
+// the end of the last word are left zero.
+type Vector []uint64
+
+// NewVector makes the zero vector of n coordinates.
+func NewVector(n int) Vector {
+	return make(Vector, (n+63)/64)
+}
+
+// Get returns coordinate i.
+func (v Vector) Get(i int) uint {
+	return uint(v[i/64]>>(uint(i)%64)) & 1
+}
+
+// Set sets coordinate i to the low bit of b.
+func (v Vector) Set(i int, b uint) {
+	mask := uint64(1) << (uint(i) % 64)
+	if b&1 == 1 {
+		v[i/64] |= mask
+	} else {
+		v[i/64] &^= mask
+	}
+}
+
+// Flip adds 1 to coordinate i.
+func (v Vector) Flip(i int) {
+	v[i/64] ^= 1 << (uint(i) % 64)
+}
+
+// Xor adds w to v in place.
+func (v Vector) Xor(w Vector) {
+	for i := range v {
+		v[i] ^= w[i]
+	}
+}
+
+// Dot returns the inner product of v and w.
+func (v Vector) Dot(w Vector) uint {
+	var parity uint64
+	for i := range v {
+		parity ^= v[i] & w[i]
+	}
+	return uint(bits.OnesCount64(parity)) & 1
+}
+
+// IsZero tells whether v is the zero vector.
+func (v Vector) IsZero() bool {
+	for _, word := range v {
+		if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Weight returns the number of ones in v.
+func (v Vector) Weight() int {
+	weight := 0
+	for _, word := range v {
+		weight += bits.OnesCount64(word)
+	}
+	return weight
+}
+
+// Clone returns a copy of v.
+func (v Vector) Clone() Vector {
+	return append(Vector{}, v...)
+}
+
+// Equal tells whether v and w are the same vector.
+func (v Vector) Equal(w Vector) bool {
+	if len(v) != len(w) {
+		return false
+	}
+	for i := range v {
+		if v[i] != w[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Bits formats the first n coordinates of v as 0s and 1s.
+func (v Vector) Bits(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte('0' + byte(v.Get(i)))
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package gf2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVector(t *testing.T) {
+	v := NewVector(130)
+	assert.Len(t, v, 3)
+	v.Set(0, 1)
+	v.Set(64, 1)
+	v.Flip(129)
+	assert.Equal(t, uint(1), v.Get(129))
+	assert.Equal(t, 3, v.Weight())
+	v.Set(64, 0)
+	assert.Equal(t, uint(0), v.Get(64))
+	assert.Equal(t, "1000", v.Bits(4))
+
+	w := v.Clone()
+	assert.True(t, w.Equal(v))
+	w.Flip(5)
+	assert.False(t, w.Equal(v))
+	assert.Equal(t, uint(0), v.Dot(w))
+	w.Flip(0)
+	assert.Equal(t, uint(1), v.Dot(w))
+
+	w.Xor(w)
+	assert.True(t, w.IsZero())
+	assert.False(t, v.IsZero())
+}
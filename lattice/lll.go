@@ -0,0 +1,234 @@
+package lattice
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Vector is a vector of rationals, a row of a lattice basis.
+type Vector []*big.Rat
+
+// NewVector makes a vector of integers.
+func NewVector(xs ...int64) Vector {
+	v := make(Vector, len(xs))
+	for i, x := range xs {
+		v[i] = new(big.Rat).SetInt64(x)
+	}
+	return v
+}
+
+// Copy returns a deep copy of v.
+func (v Vector) Copy() Vector {
+	w := make(Vector, len(v))
+	for i, x := range v {
+		w[i] = new(big.Rat).Set(x)
+	}
+	return w
+}
+
+// Dot returns the inner product of v and w.
+func (v Vector) Dot(w Vector) *big.Rat {
+	sum := new(big.Rat)
+	t := new(big.Rat)
+	for i := range v {
+		sum.Add(sum, t.Mul(v[i], w[i]))
+	}
+	return sum
+}
+
+// Equal tells whether v and w are the same vector.
+func (v Vector) Equal(w Vector) bool {
+	if len(v) != len(w) {
+		return false
+	}
+	for i := range v {
+		if v[i].Cmp(w[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// subMul sets v to v - q*w.
+func (v Vector) subMul(q *big.Rat, w Vector) {
+	t := new(big.Rat)
+	for i := range v {
+		v[i].Sub(v[i], t.Mul(q, w[i]))
+	}
+}
+
+// GramSchmidt returns the orthogonal basis b*_i = b_i - sum mu_ij*b*_j,
+// with mu_ij = b_i.b*_j / b*_j.b*_j, of the span of the basis, keeping the
+// order of the vectors. A vector in the span of the previous ones gives 0.
+func GramSchmidt(basis []Vector) []Vector {
+	ortho := make([]Vector, len(basis))
+	norms := make([]*big.Rat, len(basis))
+	for i, b := range basis {
+		ortho[i] = b.Copy()
+		for j := 0; j < i; j++ {
+			if norms[j].Sign() == 0 {
+				continue
+			}
+			mu := b.Dot(ortho[j])
+			ortho[i].subMul(mu.Quo(mu, norms[j]), ortho[j])
+		}
+		norms[i] = ortho[i].Dot(ortho[i])
+	}
+	return ortho
+}
+
+// ErrDependent is returned by LLL when the basis vectors aren't linearly
+// independent.
+var ErrDependent = errors.New("basis vectors are linearly dependent")
+
+// DefaultDelta is the usual Lovász constant, 99/100.
+var DefaultDelta = big.NewRat(99, 100)
+
+// gramSchmidt is the Gram-Schmidt data LLL keeps up to date instead of
+// recomputing it: mu[i][j] for j < i and the squared norms b[i] of the
+// orthogonal vectors. Rows above kmax haven't been computed yet.
+type gramSchmidt struct {
+	mu   [][]*big.Rat
+	b    []*big.Rat
+	kmax int
+}
+
+// compute fills row k from the basis with
+// mu_kj = (b_k.b_j - sum_{i<j} mu_ji*mu_ki*B_i) / B_j, which only needs
+// the cached rows rather than the orthogonal vectors themselves.
+func (gs *gramSchmidt) compute(basis []Vector, k int) error {
+	t := new(big.Rat)
+	for j := 0; j < k; j++ {
+		mu := basis[k].Dot(basis[j])
+		for i := 0; i < j; i++ {
+			t.Mul(gs.mu[j][i], gs.mu[k][i])
+			mu.Sub(mu, t.Mul(t, gs.b[i]))
+		}
+		gs.mu[k][j] = mu.Quo(mu, gs.b[j])
+	}
+	norm := basis[k].Dot(basis[k])
+	for j := 0; j < k; j++ {
+		t.Mul(gs.mu[k][j], gs.mu[k][j])
+		norm.Sub(norm, t.Mul(t, gs.b[j]))
+	}
+	if norm.Sign() == 0 {
+		return ErrDependent
+	}
+	gs.b[k] = norm
+	return nil
+}
+
+// reduce makes |mu_kl| <= 1/2 by subtracting the closest integer multiple
+// of b_l from b_k.
+func (gs *gramSchmidt) reduce(basis []Vector, k, l int) {
+	mu := gs.mu[k][l]
+	if new(big.Rat).Abs(mu).Cmp(half) <= 0 {
+		return
+	}
+	q := new(big.Rat).SetInt(round(mu))
+	basis[k].subMul(q, basis[l])
+	mu.Sub(mu, q)
+	t := new(big.Rat)
+	for i := 0; i < l; i++ {
+		gs.mu[k][i].Sub(gs.mu[k][i], t.Mul(q, gs.mu[l][i]))
+	}
+}
+
+// swap exchanges b_k and b_(k-1) and updates the cache for the rows up to
+// kmax, which are the only ones it holds.
+func (gs *gramSchmidt) swap(basis []Vector, k int) {
+	basis[k], basis[k-1] = basis[k-1], basis[k]
+	for j := 0; j < k-1; j++ {
+		gs.mu[k][j], gs.mu[k-1][j] = gs.mu[k-1][j], gs.mu[k][j]
+	}
+	mu := gs.mu[k][k-1]
+	// B = B_k + mu**2 * B_(k-1) is the new B_(k-1)
+	b := new(big.Rat).Mul(mu, mu)
+	b.Mul(b, gs.b[k-1]).Add(b, gs.b[k])
+	newMu := new(big.Rat).Mul(mu, gs.b[k-1])
+	newMu.Quo(newMu, b)
+	newB := new(big.Rat).Mul(gs.b[k-1], gs.b[k])
+	gs.b[k] = newB.Quo(newB, b)
+	gs.b[k-1] = b
+	gs.mu[k][k-1] = newMu
+	for i := k + 1; i <= gs.kmax; i++ {
+		t := gs.mu[i][k]
+		muIK := new(big.Rat).Mul(mu, t)
+		gs.mu[i][k] = muIK.Sub(gs.mu[i][k-1], muIK)
+		muIK1 := new(big.Rat).Mul(newMu, gs.mu[i][k])
+		gs.mu[i][k-1] = muIK1.Add(muIK1, t)
+	}
+}
+
+var half = big.NewRat(1, 2)
+
+// round returns the integer closest to x, rounding halves up.
+func round(x *big.Rat) *big.Int {
+	n := new(big.Rat).Add(x, half)
+	q := new(big.Int).Div(n.Num(), n.Denom())
+	return q
+}
+
+// LLL returns a reduced basis of the lattice spanned by basis, made of
+// short, nearly orthogonal vectors: |mu_ij| <= 1/2 and the Lovász condition
+// B_k >= (delta - mu_k,k-1**2) * B_(k-1) for every k. delta in (1/4, 1)
+// trades running time for quality; nil means DefaultDelta. The input isn't
+// modified. The Gram-Schmidt coefficients are updated in place as vectors
+// are reduced and swapped, rather than recomputed, as in Cohen's algorithm
+// 2.6.3.
+func LLL(basis []Vector, delta *big.Rat) ([]Vector, error) {
+	if delta == nil {
+		delta = DefaultDelta
+	}
+	if delta.Cmp(big.NewRat(1, 4)) <= 0 || delta.Cmp(big.NewRat(1, 1)) >= 0 {
+		return nil, errors.New("delta must be in (1/4, 1)")
+	}
+	n := len(basis)
+	reduced := make([]Vector, n)
+	for i, b := range basis {
+		if len(b) != len(basis[0]) {
+			return nil, errors.New("basis vectors of different lengths")
+		}
+		reduced[i] = b.Copy()
+	}
+	if n == 0 {
+		return reduced, nil
+	}
+
+	gs := &gramSchmidt{
+		mu: make([][]*big.Rat, n),
+		b:  make([]*big.Rat, n),
+	}
+	for i := range gs.mu {
+		gs.mu[i] = make([]*big.Rat, i)
+	}
+	if err := gs.compute(reduced, 0); err != nil {
+		return nil, err
+	}
+
+	t := new(big.Rat)
+	for k := 1; k < n; {
+		if k > gs.kmax {
+			gs.kmax = k
+			if err := gs.compute(reduced, k); err != nil {
+				return nil, err
+			}
+		}
+		gs.reduce(reduced, k, k-1)
+		// Lovász condition
+		t.Mul(gs.mu[k][k-1], gs.mu[k][k-1])
+		t.Sub(delta, t).Mul(t, gs.b[k-1])
+		if gs.b[k].Cmp(t) < 0 {
+			gs.swap(reduced, k)
+			if k > 1 {
+				k--
+			}
+			continue
+		}
+		for l := k - 2; l >= 0; l-- {
+			gs.reduce(reduced, k, l)
+		}
+		k++
+	}
+	return reduced, nil
+}
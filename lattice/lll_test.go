@@ -0,0 +1,114 @@
+package lattice
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rat(a, b int64) *big.Rat {
+	return big.NewRat(a, b)
+}
+
+func TestGramSchmidt(t *testing.T) {
+	basis := []Vector{NewVector(3, 1), NewVector(2, 2)}
+	ortho := GramSchmidt(basis)
+	assert.True(t, ortho[0].Equal(basis[0]))
+	assert.True(t, ortho[1].Equal(Vector{rat(-2, 5), rat(6, 5)}))
+	assert.Equal(t, 0, ortho[0].Dot(ortho[1]).Sign())
+
+	// a dependent vector gives 0
+	ortho = GramSchmidt([]Vector{NewVector(1, 2), NewVector(2, 4)})
+	assert.True(t, ortho[1].Equal(NewVector(0, 0)))
+}
+
+func TestLLL(t *testing.T) {
+	// the example of challenge 62
+	basis := []Vector{
+		{rat(-2, 1), rat(0, 1), rat(2, 1), rat(0, 1)},
+		{rat(1, 2), rat(-1, 1), rat(0, 1), rat(0, 1)},
+		{rat(-1, 1), rat(0, 1), rat(-2, 1), rat(1, 2)},
+		{rat(-1, 1), rat(1, 1), rat(1, 1), rat(2, 1)},
+	}
+	reduced, err := LLL(basis, nil)
+	assert.NoError(t, err)
+	expected := []Vector{
+		{rat(1, 2), rat(-1, 1), rat(0, 1), rat(0, 1)},
+		{rat(-1, 1), rat(0, 1), rat(-2, 1), rat(1, 2)},
+		{rat(-1, 2), rat(0, 1), rat(1, 1), rat(2, 1)},
+		{rat(-3, 2), rat(-1, 1), rat(2, 1), rat(0, 1)},
+	}
+	for i := range expected {
+		assert.True(t, reduced[i].Equal(expected[i]), "vector %d: %v", i, reduced[i])
+	}
+	// the input is left alone
+	assert.True(t, basis[0].Equal(NewVector(-2, 0, 2, 0)))
+
+	_, err = LLL([]Vector{NewVector(1, 2), NewVector(2, 4)}, nil)
+	assert.Equal(t, ErrDependent, err)
+	_, err = LLL(basis, rat(1, 5))
+	assert.Error(t, err)
+	_, err = LLL(basis, rat(1, 4))
+	assert.Error(t, err)
+	_, err = LLL(basis, rat(1, 1))
+	assert.Error(t, err)
+}
+
+// checkReduced checks the two conditions of an LLL-reduced basis.
+func checkReduced(t *testing.T, basis []Vector, delta *big.Rat) {
+	ortho := GramSchmidt(basis)
+	norms := make([]*big.Rat, len(ortho))
+	for i := range ortho {
+		norms[i] = ortho[i].Dot(ortho[i])
+	}
+	for k := 1; k < len(basis); k++ {
+		for j := 0; j < k; j++ {
+			mu := basis[k].Dot(ortho[j])
+			mu.Quo(mu, norms[j])
+			assert.True(t, mu.Abs(mu).Cmp(half) <= 0, "mu[%d][%d] = %v", k, j, mu)
+		}
+		mu := basis[k].Dot(ortho[k-1])
+		mu.Quo(mu, norms[k-1])
+		bound := new(big.Rat).Mul(mu, mu)
+		bound.Sub(delta, bound).Mul(bound, norms[k-1])
+		assert.True(t, norms[k].Cmp(bound) >= 0, "Lovász condition at %d", k)
+	}
+}
+
+func TestLLLRandom(t *testing.T) {
+	random := rand.New(rand.NewSource(62))
+	for n := 2; n <= 8; n++ {
+		basis := make([]Vector, n)
+		for i := range basis {
+			basis[i] = make(Vector, n)
+			for j := range basis[i] {
+				basis[i][j] = new(big.Rat).SetInt64(random.Int63n(2001) - 1000)
+			}
+		}
+		reduced, err := LLL(basis, nil)
+		if err == ErrDependent {
+			continue
+		}
+		assert.NoError(t, err)
+		checkReduced(t, reduced, DefaultDelta)
+
+		// the volume of the lattice doesn't change
+		volume := func(b []Vector) *big.Rat {
+			v := big.NewRat(1, 1)
+			for _, o := range GramSchmidt(b) {
+				v.Mul(v, o.Dot(o))
+			}
+			return v
+		}
+		assert.Equal(t, 0, volume(basis).Cmp(volume(reduced)))
+	}
+}
+
+func TestRound(t *testing.T) {
+	assert.Equal(t, "2", round(rat(3, 2)).String())
+	assert.Equal(t, "-1", round(rat(-3, 2)).String())
+	assert.Equal(t, "-2", round(rat(-5, 3)).String())
+	assert.Equal(t, "0", round(rat(1, 3)).String())
+}